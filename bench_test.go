@@ -0,0 +1,84 @@
+package brulee
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const benchmarkSource = `
+score(politics) = 0
+score(sports) = 0
+when
+  var(title) contains "labour"
+  or var(title) contains "brexit"
+then
+  score(politics) += 10
+  when
+    var(title) contains "party"
+  then
+    score(politics) *= 1.5
+  done
+done
+when
+  var(topic) in ["elections", "eu", "economics"]
+then
+  score(politics) += 5
+done
+when
+  var(title) matches /(snow|skate)board(ing|er)/
+  or (var(title) contains "running" and var(title) does not contain "zombies")
+then
+  score(sports) = score(sports) * 2 + 10
+done
+`
+
+var benchmarkVars = map[string]string{
+	"title": "labour party conference",
+	"topic": "elections",
+}
+
+func TestProgram_Concurrent(t *testing.T) {
+	program := MustCompile(strings.NewReader(benchmarkSource))
+	expected := map[string]float64{"politics": 20, "sports": 0}
+
+	var wg sync.WaitGroup
+	for n := 0; n < 8; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				scores, err := program.RunFloat(benchmarkVars)
+				assert.NoError(t, err)
+				assert.Equal(t, expected, scores)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkProgram_Run(b *testing.B) {
+	program := MustCompile(strings.NewReader(benchmarkSource))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := program.Run(benchmarkVars); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkProgram_Run_Parallel(b *testing.B) {
+	program := MustCompile(strings.NewReader(benchmarkSource))
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := program.Run(benchmarkVars); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -1,63 +1,203 @@
 package brulee
 
 import (
+	"context"
 	"io"
-	"strconv"
 
 	"github.com/nick-jones/brulee/internal"
-	"github.com/olekukonko/tablewriter"
 	"github.com/pkg/errors"
 )
 
-func Compile(r io.Reader) (Program, error) {
+// Error is an error attributed to a position within the program source. Errors returned from Compile and Run may
+// be inspected for this type via errors.As.
+type Error = internal.Error
+
+// ErrorList holds every error found when compiling a program.
+type ErrorList = internal.ErrorList
+
+// Compile parses and compiles a program, reporting every error found. Compilation may be configured via options such
+// as DeclareVariables.
+func Compile(r io.Reader, opts ...CompileOption) (Program, error) {
 	program := Program{}
+	co := newCompileOptions(opts)
 	root, err := internal.Parse(r)
 	if err != nil {
 		return program, errors.Wrap(err, "parse failure")
 	}
+	tc := internal.NewTypeChecker()
+	if co.declared {
+		tc.DeclareVariables(co.vars)
+	}
+	tc.Check(root)
 	ig := internal.NewInstructionsGenerator()
 	ig.Generate(root)
-	if err := ig.Err(); err != nil {
-		return program, errors.Wrap(err, "instructions generation failure")
+	if errs := append(tc.Errors(), ig.Errors()...); len(errs) > 0 {
+		errs.Sort()
+		return program, errors.Wrap(errs, "compile failure")
 	}
-	program.load(ig.Instructions())
+	program.load(ig.Instructions(), internal.NewSchema(root.Input))
 	return program, nil
 }
 
-func MustCompile(r io.Reader) Program {
-	program, err := Compile(r)
+func MustCompile(r io.Reader, opts ...CompileOption) Program {
+	program, err := Compile(r, opts...)
 	if err != nil {
 		panic(err)
 	}
 	return program
 }
 
+// Program is a compiled program. It is safe for concurrent use: runs draw upon a pool of executors, such that
+// steady-state runs allocate only the scores they return.
 type Program struct {
-	ins []internal.Instruction
+	ins    []internal.Instruction
+	schema internal.Schema
+	pool   *internal.ExecutorPool
+}
+
+// Value is a typed variable value, as constructed by String, Int, Float, Bool or List.
+type Value = internal.Value
+
+func String(s string) Value {
+	return internal.NewStringValue(s)
+}
+
+func Int(n int) Value {
+	return internal.NewIntValue(n)
+}
+
+func Float(f float64) Value {
+	return internal.NewFloatValue(f)
+}
+
+func Bool(b bool) Value {
+	return internal.NewBoolValue(b)
+}
+
+func List(items ...string) Value {
+	return internal.NewListValue(items)
 }
 
+// Run executes the program against the supplied variables. Float scores are truncated; use RunFloat to retain them.
 func (p Program) Run(vars map[string]string) (map[string]int, error) {
-	i := internal.NewExecutor(p.ins, vars)
-	i.Execute()
-	if err := i.Err(); err != nil {
+	i, err := p.run(internal.StringVariables(vars), runOptions{})
+	defer p.release(i)
+	if err != nil {
+		return nil, err
+	}
+	return i.IntScores(), nil
+}
+
+// RunValues is the equivalent of Run for typed variables.
+func (p Program) RunValues(vars map[string]Value) (map[string]int, error) {
+	i, err := p.run(internal.Values(vars), runOptions{})
+	defer p.release(i)
+	if err != nil {
 		return nil, err
 	}
-	return i.Scores(), nil
+	return i.IntScores(), nil
 }
 
-func (p Program) Dump(w io.Writer) {
-	table := tablewriter.NewWriter(w)
-	table.SetHeader([]string{"Pos", "Op", "Ret", "Operand1", "Operand2"})
+func (p Program) RunFloat(vars map[string]string) (map[string]float64, error) {
+	i, err := p.run(internal.StringVariables(vars), runOptions{})
+	defer p.release(i)
+	if err != nil {
+		return nil, err
+	}
+	return i.FloatScores(), nil
+}
 
-	for i, in := range p.ins {
-		s := []string{strconv.Itoa(i)}
-		s = append(s, in.StringSlice()...)
-		table.Append(s)
+func (p Program) RunValuesFloat(vars map[string]Value) (map[string]float64, error) {
+	i, err := p.run(internal.Values(vars), runOptions{})
+	defer p.release(i)
+	if err != nil {
+		return nil, err
 	}
+	return i.FloatScores(), nil
+}
 
-	table.Render()
+// RunContext is the equivalent of Run, aborting should the context be done before the run completes. Further bounds
+// may be placed on the run via options such as MaxInstructions and Timeout, and undefined variables and scores
+// rejected via Strict.
+func (p Program) RunContext(ctx context.Context, vars map[string]string, opts ...RunOption) (map[string]int, error) {
+	i, err := p.run(internal.StringVariables(vars), newRunOptions(ctx, opts))
+	defer p.release(i)
+	if err != nil {
+		return nil, err
+	}
+	return i.IntScores(), nil
+}
+
+// RunValuesContext is the equivalent of RunContext for typed variables.
+func (p Program) RunValuesContext(ctx context.Context, vars map[string]Value, opts ...RunOption) (map[string]int, error) {
+	i, err := p.run(internal.Values(vars), newRunOptions(ctx, opts))
+	defer p.release(i)
+	if err != nil {
+		return nil, err
+	}
+	return i.IntScores(), nil
+}
+
+// run executes the program, returning the executor for the results to be read from. The caller is responsible for
+// releasing the executor once done with it. Variables are first validated against the program's input block.
+func (p Program) run(vars internal.Variables, opts runOptions) (*internal.Executor, error) {
+	var i *internal.Executor
+	if p.pool != nil {
+		i = p.pool.Get(vars)
+	} else {
+		i = internal.NewExecutor(p.ins, vars)
+	}
+	if err := p.schema.Validate(vars); err != nil {
+		return i, err
+	}
+	if opts.trace {
+		i.EnableTrace()
+	}
+	if opts.strict {
+		i.EnableStrict()
+	}
+	i.SetLimits(opts.limits)
+	i.Execute()
+	return i, i.Err()
+}
+
+func (p Program) release(i *internal.Executor) {
+	if p.pool != nil {
+		p.pool.Put(i)
+	}
+}
+
+// Rules returns the names of the named rules within the program, in the order in which they appear.
+func (p Program) Rules() []string {
+	var rules []string
+	seen := map[string]bool{}
+	for _, in := range p.ins {
+		if in.Rule != "" && !seen[in.Rule] {
+			seen[in.Rule] = true
+			rules = append(rules, in.Rule)
+		}
+	}
+	return rules
+}
+
+// MarshalBinary encodes the compiled program, such that it may be loaded via UnmarshalBinary without recompiling.
+func (p Program) MarshalBinary() ([]byte, error) {
+	return internal.EncodeProgram(p.ins, p.schema)
+}
+
+// UnmarshalBinary loads a program encoded by MarshalBinary. The encoding is versioned, and programs encoded by an
+// incompatible version are rejected, as are those that fail validation.
+func (p *Program) UnmarshalBinary(data []byte) error {
+	ins, schema, err := internal.DecodeProgram(data)
+	if err != nil {
+		return errors.Wrap(err, "decode failure")
+	}
+	p.load(ins, schema)
+	return nil
 }
 
-func (p *Program) load(ins []internal.Instruction) {
+func (p *Program) load(ins []internal.Instruction, schema internal.Schema) {
 	p.ins = ins
+	p.schema = schema
+	p.pool = internal.NewExecutorPool(ins)
 }
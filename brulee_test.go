@@ -1,7 +1,10 @@
 package brulee
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"strconv"
 	"strings"
@@ -14,16 +17,68 @@ import (
 
 var (
 	program Program
+	source  string
 	vars    map[string]string
+	values  map[string]Value
 	scores  map[string]int
+
+	explanation Explanation
+	compileOpts []CompileOption
+	runOpts     []RunOption
 )
 
 func theProgram(p *messages.PickleStepArgument_PickleDocString) error {
 	var err error
-	program, err = Compile(strings.NewReader(p.Content))
+	program, err = Compile(strings.NewReader(p.Content), compileOpts...)
 	return err
 }
 
+func theInvalidProgram(p *messages.PickleStepArgument_PickleDocString) error {
+	source = p.Content
+	return nil
+}
+
+func theDeclaredVariablesAre(table *messages.PickleStepArgument_PickleTable) error {
+	names := make([]string, 0, len(table.Rows)-1)
+	for _, row := range table.Rows[1:] {
+		names = append(names, row.Cells[0].Value)
+	}
+	compileOpts = append(compileOpts, DeclareVariables(names...))
+	return nil
+}
+
+func theProgramFailsToCompileAt(line, column int) error {
+	_, err := Compile(strings.NewReader(source), compileOpts...)
+	return errorAt(err, line, column)
+}
+
+func theProgramFailsToCompileWith(p *messages.PickleStepArgument_PickleDocString) error {
+	_, err := Compile(strings.NewReader(source), compileOpts...)
+	if err == nil {
+		return fmt.Errorf("expected error %q, program compiled successfully", p.Content)
+	}
+	if err.Error() != p.Content {
+		return fmt.Errorf("error mismatch, expected %q, actual %q", p.Content, err.Error())
+	}
+	return nil
+}
+
+func theProgramRunFailsAt(line, column int) error {
+	_, err := runProgram()
+	return errorAt(err, line, column)
+}
+
+func errorAt(err error, line, column int) error {
+	var perr *Error
+	if !errors.As(err, &perr) {
+		return fmt.Errorf("expected positioned error, actual %v", err)
+	}
+	if perr.Pos.Line != line || perr.Pos.Column != column {
+		return fmt.Errorf("position mismatch, expected %d:%d, actual %d:%d", line, column, perr.Pos.Line, perr.Pos.Column)
+	}
+	return nil
+}
+
 func variables(table *messages.PickleStepArgument_PickleTable) error {
 	for _, row := range table.Rows[1:] {
 		vars[row.Cells[0].Value] = row.Cells[1].Value
@@ -31,12 +86,113 @@ func variables(table *messages.PickleStepArgument_PickleTable) error {
 	return nil
 }
 
+func typedVariables(table *messages.PickleStepArgument_PickleTable) error {
+	for _, row := range table.Rows[1:] {
+		value, err := parseValue(row.Cells[1].Value, row.Cells[2].Value)
+		if err != nil {
+			return err
+		}
+		values[row.Cells[0].Value] = value
+	}
+	return nil
+}
+
+func parseValue(typ, s string) (Value, error) {
+	switch typ {
+	case "string":
+		return String(s), nil
+	case "int":
+		n, err := strconv.Atoi(s)
+		return Int(n), err
+	case "float":
+		f, err := strconv.ParseFloat(s, 64)
+		return Float(f), err
+	case "bool":
+		b, err := strconv.ParseBool(s)
+		return Bool(b), err
+	case "list":
+		if s == "" {
+			return List(), nil
+		}
+		return List(strings.Split(s, ",")...), nil
+	}
+	return Value{}, fmt.Errorf("unknown value type %s", typ)
+}
+
+func runProgram() (map[string]int, error) {
+	if len(runOpts) > 0 {
+		if len(values) > 0 {
+			return program.RunValuesContext(context.Background(), values, runOpts...)
+		}
+		return program.RunContext(context.Background(), vars, runOpts...)
+	}
+	if len(values) > 0 {
+		return program.RunValues(values)
+	}
+	return program.Run(vars)
+}
+
+func theProgramIsReloadedFromBinary() error {
+	data, err := program.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	program = Program{}
+	return program.UnmarshalBinary(data)
+}
+
+func theDumpIs(format string, p *messages.PickleStepArgument_PickleDocString) error {
+	formats := map[string]DumpFormat{"plain": DumpFormatPlain, "JSON": DumpFormatJSON}
+	var buf strings.Builder
+	if err := program.DumpAs(&buf, formats[format]); err != nil {
+		return err
+	}
+	if actual := strings.TrimSuffix(buf.String(), "\n"); actual != p.Content {
+		return fmt.Errorf("dump mismatch, expected:\n%s\nactual:\n%s", p.Content, actual)
+	}
+	return nil
+}
+
+func theGraphIs(p *messages.PickleStepArgument_PickleDocString) error {
+	var buf strings.Builder
+	if err := program.DumpGraph(&buf); err != nil {
+		return err
+	}
+	if actual := strings.TrimSuffix(buf.String(), "\n"); actual != p.Content {
+		return fmt.Errorf("graph mismatch, expected:\n%s\nactual:\n%s", p.Content, actual)
+	}
+	return nil
+}
+
+func runsAreLimitedToInstructions(n int) error {
+	runOpts = append(runOpts, MaxInstructions(n))
+	return nil
+}
+
+func runsAreStrict() error {
+	runOpts = append(runOpts, Strict())
+	return nil
+}
+
 func theProgramIsRun() error {
 	var err error
-	scores, err = program.Run(vars)
+	scores, err = runProgram()
 	return err
 }
 
+func theProgramRunFailsWith(p *messages.PickleStepArgument_PickleDocString) error {
+	expected := p.Content
+	var err error
+	scores, err = runProgram()
+	if err == nil {
+		return fmt.Errorf("expected error %q, program ran successfully", expected)
+	}
+	if err.Error() != expected {
+		return fmt.Errorf("error mismatch, expected %q, actual %q", expected, err.Error())
+	}
+	return nil
+}
+
 func theScoreOutputIs(table *messages.PickleStepArgument_PickleTable) error {
 	if len(table.Rows)-1 != len(scores) {
 		return fmt.Errorf("row count mismatch, expected %d, actual %d", len(table.Rows)-1, len(scores))
@@ -54,6 +210,85 @@ func theScoreOutputIs(table *messages.PickleStepArgument_PickleTable) error {
 	return nil
 }
 
+func theFloatScoreOutputIs(table *messages.PickleStepArgument_PickleTable) error {
+	var floatScores map[string]float64
+	var err error
+	if len(values) > 0 {
+		floatScores, err = program.RunValuesFloat(values)
+	} else {
+		floatScores, err = program.RunFloat(vars)
+	}
+	if err != nil {
+		return err
+	}
+	if len(table.Rows)-1 != len(floatScores) {
+		return fmt.Errorf("row count mismatch, expected %d, actual %d", len(table.Rows)-1, len(floatScores))
+	}
+	for _, row := range table.Rows[1:] {
+		name := row.Cells[0].Value
+		value, err := strconv.ParseFloat(row.Cells[1].Value, 64)
+		if err != nil {
+			return err
+		}
+		if math.Abs(floatScores[name]-value) > 1e-9 {
+			return fmt.Errorf("score mismatch for %s, expected %v, actual %v", name, value, floatScores[name])
+		}
+	}
+	return nil
+}
+
+func theProgramIsExplained() error {
+	var err error
+	if len(values) > 0 {
+		explanation, err = program.ExplainValues(values)
+	} else {
+		explanation, err = program.Explain(vars)
+	}
+	return err
+}
+
+func theTraceIs(p *messages.PickleStepArgument_PickleDocString) error {
+	lines := make([]string, len(explanation.Trace))
+	for i, te := range explanation.Trace {
+		lines[i] = te.String()
+	}
+	if actual := strings.Join(lines, "\n"); actual != p.Content {
+		return fmt.Errorf("trace mismatch, expected:\n%s\nactual:\n%s", p.Content, actual)
+	}
+	return nil
+}
+
+func theAttributionIs(table *messages.PickleStepArgument_PickleTable) error {
+	if len(table.Rows)-1 != len(explanation.Attribution) {
+		return fmt.Errorf("row count mismatch, expected %d, actual %d", len(table.Rows)-1, len(explanation.Attribution))
+	}
+	for _, row := range table.Rows[1:] {
+		name := row.Cells[0].Value
+		contributions := explanation.Attribution[name]
+		parts := make([]string, len(contributions))
+		for i, c := range contributions {
+			parts[i] = c.String()
+		}
+		if actual := strings.Join(parts, ", "); actual != row.Cells[1].Value {
+			return fmt.Errorf("attribution mismatch for %s, expected %q, actual %q", name, row.Cells[1].Value, actual)
+		}
+	}
+	return nil
+}
+
+func theRulesAre(table *messages.PickleStepArgument_PickleTable) error {
+	rules := program.Rules()
+	if len(table.Rows)-1 != len(rules) {
+		return fmt.Errorf("row count mismatch, expected %d, actual %d", len(table.Rows)-1, len(rules))
+	}
+	for i, row := range table.Rows[1:] {
+		if rules[i] != row.Cells[0].Value {
+			return fmt.Errorf("rule mismatch at %d, expected %s, actual %s", i, row.Cells[0].Value, rules[i])
+		}
+	}
+	return nil
+}
+
 func theScoreOutputIsEmpty() error {
 	if len(scores) != 0 {
 		return fmt.Errorf("score output expected to be empty, actual %d", len(scores))
@@ -65,13 +300,34 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.BeforeScenario(func(_ *godog.Scenario) {
 		program = Program{}
 		vars = map[string]string{}
+		values = map[string]Value{}
 		scores = map[string]int{}
+		explanation = Explanation{}
+		compileOpts = nil
+		runOpts = nil
 	})
+	ctx.Step(`^the declared variables are:$`, theDeclaredVariablesAre)
 	ctx.Step(`^the program:$`, theProgram)
+	ctx.Step(`^the invalid program:$`, theInvalidProgram)
+	ctx.Step(`^the program fails to compile at (\d+):(\d+)$`, theProgramFailsToCompileAt)
+	ctx.Step(`^the program fails to compile with:$`, theProgramFailsToCompileWith)
+	ctx.Step(`^the program run fails at (\d+):(\d+)$`, theProgramRunFailsAt)
 	ctx.Step(`^variables:$`, variables)
+	ctx.Step(`^typed variables:$`, typedVariables)
 	ctx.Step(`^the program is run$`, theProgramIsRun)
+	ctx.Step(`^the program is reloaded from binary$`, theProgramIsReloadedFromBinary)
+	ctx.Step(`^the (plain|JSON) dump is:$`, theDumpIs)
+	ctx.Step(`^the graph is:$`, theGraphIs)
+	ctx.Step(`^runs are limited to (\d+) instructions$`, runsAreLimitedToInstructions)
+	ctx.Step(`^runs are strict$`, runsAreStrict)
+	ctx.Step(`^the program is explained$`, theProgramIsExplained)
+	ctx.Step(`^the trace is:$`, theTraceIs)
+	ctx.Step(`^the attribution is:$`, theAttributionIs)
+	ctx.Step(`^the rules are:$`, theRulesAre)
+	ctx.Step(`^the program run fails with:$`, theProgramRunFailsWith)
 	ctx.Step(`^the score output is:$`, theScoreOutputIs)
 	ctx.Step(`^the score output is empty$`, theScoreOutputIsEmpty)
+	ctx.Step(`^the float score output is:$`, theFloatScoreOutputIs)
 }
 
 func TestMain(m *testing.M) {
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// runCompile compiles a rule file, reporting success to out. Where an output file is named, the compiled program
+// is written to it in binary form.
+func runCompile(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("compile", flag.ContinueOnError)
+	output := fs.String("o", "", "write the compiled program to the named file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	name, err := singleFile(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	program, err := compileFile(name)
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		data, err := program.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(*output, data, 0644); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(out, "%s: ok\n", name)
+	return err
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/nick-jones/brulee"
+)
+
+var dumpFormats = map[string]brulee.DumpFormat{
+	"table": brulee.DumpFormatTable,
+	"json":  brulee.DumpFormatJSON,
+	"plain": brulee.DumpFormatPlain,
+}
+
+// runDump writes the instructions compiled from a rule file to out, in the requested format. The "dot" format
+// renders the control flow graph rather than the instruction listing.
+func runDump(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	format := fs.String("format", "table", "output format: table, json, plain or dot")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	name, err := singleFile(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	program, err := compileFile(name)
+	if err != nil {
+		return err
+	}
+	if *format == "dot" {
+		return program.DumpGraph(out)
+	}
+	df, ok := dumpFormats[*format]
+	if !ok {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	return program.DumpAs(out, df)
+}
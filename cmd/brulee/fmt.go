@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/nick-jones/brulee"
+)
+
+var errUnformatted = errors.New("files are not formatted")
+
+// runFmt formats the named files, or stdin where none are named, writing the result to out. In check mode, the
+// names of files that are not already formatted are written instead, and errUnformatted returned if there are any.
+func runFmt(args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	check := fs.Bool("check", false, "list files whose formatting differs, exiting non-zero if there are any")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		src, err := ioutil.ReadAll(in)
+		if err != nil {
+			return err
+		}
+		return formatSource("<stdin>", src, *check, out)
+	}
+	unformatted := false
+	for _, name := range fs.Args() {
+		src, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if err := formatSource(name, src, *check, out); err == errUnformatted {
+			unformatted = true
+		} else if err != nil {
+			return err
+		}
+	}
+	if unformatted {
+		return errUnformatted
+	}
+	return nil
+}
+
+func formatSource(name string, src []byte, check bool, out io.Writer) error {
+	formatted, err := brulee.Format(src)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	if !check {
+		_, err = out.Write(formatted)
+		return err
+	}
+	if !bytes.Equal(src, formatted) {
+		fmt.Fprintln(out, name)
+		return errUnformatted
+	}
+	return nil
+}
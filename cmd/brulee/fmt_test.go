@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunFmt(t *testing.T) {
+	var out bytes.Buffer
+	err := runFmt(nil, strings.NewReader("score(x)=1"), &out)
+	require.NoError(t, err)
+	assert.Equal(t, "score(x) = 1\n", out.String())
+}
+
+func TestRunFmt_Check(t *testing.T) {
+	dir, err := ioutil.TempDir("", "brulee")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	formatted := filepath.Join(dir, "formatted.brl")
+	unformatted := filepath.Join(dir, "unformatted.brl")
+	empty := filepath.Join(dir, "empty.brl")
+	require.NoError(t, ioutil.WriteFile(formatted, []byte("score(x) = 1\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(unformatted, []byte("score(x)=1\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(empty, nil, 0600))
+
+	var out bytes.Buffer
+	assert.NoError(t, runFmt([]string{"-check", formatted, empty}, nil, &out))
+	assert.Empty(t, out.String())
+
+	err = runFmt([]string{"-check", formatted, unformatted}, nil, &out)
+	assert.Equal(t, errUnformatted, err)
+	assert.Equal(t, unformatted+"\n", out.String())
+}
@@ -0,0 +1,68 @@
+// Command brulee works with brulee rule files.
+//
+// Usage:
+//
+//	brulee compile [-o output] file
+//	brulee dump [-format table|json|plain|dot] file
+//	brulee run [-var name=value ...] [-vars file.json] [-json] file
+//	brulee fmt [-check] [file ...]
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nick-jones/brulee"
+)
+
+const usage = `usage: brulee <command> [arguments]
+
+commands:
+  compile  compile a rule file, reporting any errors
+  dump     dump the instructions compiled from a rule file
+  run      run a rule file against variables, printing the scores
+  fmt      format rule files
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "compile":
+		err = runCompile(args, os.Stdout)
+	case "dump":
+		err = runDump(args, os.Stdout)
+	case "run":
+		err = runRun(args, os.Stdin, os.Stdout)
+	case "fmt":
+		err = runFmt(args, os.Stdin, os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "brulee: unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "brulee: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// compileFile compiles the named rule file. The file name is attached to any positions reported in errors.
+func compileFile(name string) (brulee.Program, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return brulee.Program{}, err
+	}
+	defer f.Close()
+	return brulee.Compile(f)
+}
+
+// singleFile returns the sole file named in args, erroring where there is not exactly one.
+func singleFile(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expected a single rule file, got %d arguments", len(args))
+	}
+	return args[0], nil
+}
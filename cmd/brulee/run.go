@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nick-jones/brulee"
+)
+
+// varFlags collects variables supplied as repeated name=value flags.
+type varFlags map[string]string
+
+func (vf varFlags) String() string {
+	return fmt.Sprint(map[string]string(vf))
+}
+
+func (vf varFlags) Set(s string) error {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("expected name=value, got %q", s)
+	}
+	vf[parts[0]] = parts[1]
+	return nil
+}
+
+// runRun runs a rule file against variables, writing the resulting scores to out. Variables are read from a JSON
+// object, be that a file or stdin (named "-"), and from -var flags, which take precedence.
+func runRun(args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	flagVars := varFlags{}
+	fs.Var(flagVars, "var", "set a string variable, as name=value (repeatable)")
+	varsFile := fs.String("vars", "", `read variables from a JSON object in the named file, or "-" for stdin`)
+	asJSON := fs.Bool("json", false, "print scores as a JSON object")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	name, err := singleFile(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	vars := map[string]brulee.Value{}
+	if *varsFile != "" {
+		if vars, err = readVars(*varsFile, in); err != nil {
+			return err
+		}
+	}
+	for k, v := range flagVars {
+		vars[k] = brulee.String(v)
+	}
+
+	program, err := compileFile(name)
+	if err != nil {
+		return err
+	}
+	scores, err := program.RunValuesFloat(vars)
+	if err != nil {
+		return err
+	}
+	return writeScores(out, scores, *asJSON)
+}
+
+func readVars(name string, in io.Reader) (map[string]brulee.Value, error) {
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = ioutil.ReadAll(in)
+	} else {
+		data, err = ioutil.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseVars(data)
+}
+
+// parseVars decodes variables from a JSON object. Strings, numbers, bools and arrays of strings are supported, with
+// integral numbers becoming ints.
+func parseVars(data []byte) (map[string]brulee.Value, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid variables: %v", err)
+	}
+	vars := make(map[string]brulee.Value, len(raw))
+	for name, v := range raw {
+		switch val := v.(type) {
+		case string:
+			vars[name] = brulee.String(val)
+		case float64:
+			if val == math.Trunc(val) && math.Abs(val) <= math.MaxInt32 {
+				vars[name] = brulee.Int(int(val))
+			} else {
+				vars[name] = brulee.Float(val)
+			}
+		case bool:
+			vars[name] = brulee.Bool(val)
+		case []interface{}:
+			items := make([]string, len(val))
+			for n, item := range val {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid variable %s: list items must be strings", name)
+				}
+				items[n] = s
+			}
+			vars[name] = brulee.List(items...)
+		default:
+			return nil, fmt.Errorf("invalid variable %s: unsupported type %T", name, v)
+		}
+	}
+	return vars, nil
+}
+
+func writeScores(w io.Writer, scores map[string]float64, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		return enc.Encode(scores)
+	}
+	names := make([]string, 0, len(scores))
+	for name := range scores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", name, strconv.FormatFloat(scores[name], 'f', -1, 64)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nick-jones/brulee"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProgram = `score(x) = 1
+when
+  var(a) contains "b"
+  and var(tags) contains "t"
+then
+  score(x) += var(n) * 0.5
+done
+`
+
+func writeProgram(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "brulee")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	name := filepath.Join(dir, "rules.brl")
+	require.NoError(t, ioutil.WriteFile(name, []byte(testProgram), 0600))
+	return name
+}
+
+func TestParseVars(t *testing.T) {
+	vars, err := parseVars([]byte(`{"s": "x", "i": 3, "f": 0.5, "b": true, "l": ["y", "z"]}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]brulee.Value{
+		"s": brulee.String("x"),
+		"i": brulee.Int(3),
+		"f": brulee.Float(0.5),
+		"b": brulee.Bool(true),
+		"l": brulee.List("y", "z"),
+	}, vars)
+
+	_, err = parseVars([]byte(`{"l": [1]}`))
+	assert.EqualError(t, err, "invalid variable l: list items must be strings")
+}
+
+func TestRunRun(t *testing.T) {
+	name := writeProgram(t)
+
+	var out bytes.Buffer
+	stdin := strings.NewReader(`{"a": "abc", "n": 3, "tags": ["t"]}`)
+	require.NoError(t, runRun([]string{"-vars", "-", name}, stdin, &out))
+	assert.Equal(t, "x\t2.5\n", out.String())
+
+	out.Reset()
+	stdin = strings.NewReader(`{"a": "abc", "n": 3, "tags": ["t"]}`)
+	require.NoError(t, runRun([]string{"-vars", "-", "-var", "a=c", "-json", name}, stdin, &out))
+	assert.Equal(t, "{\"x\":1}\n", out.String())
+}
+
+func TestRunCompile(t *testing.T) {
+	name := writeProgram(t)
+	output := name + ".bin"
+
+	var out bytes.Buffer
+	require.NoError(t, runCompile([]string{"-o", output, name}, &out))
+	assert.Equal(t, name+": ok\n", out.String())
+
+	data, err := ioutil.ReadFile(output)
+	require.NoError(t, err)
+	var program brulee.Program
+	assert.NoError(t, program.UnmarshalBinary(data))
+}
+
+func TestRunDump(t *testing.T) {
+	name := writeProgram(t)
+
+	var out bytes.Buffer
+	require.NoError(t, runDump([]string{"-format", "plain", name}, &out))
+	assert.True(t, strings.HasPrefix(out.String(), "0 SET_SCORE score(x) int(1) at 1:1\n"))
+
+	assert.EqualError(t, runDump([]string{"-format", "xml", name}, &out), `unknown format "xml"`)
+}
@@ -0,0 +1,146 @@
+package brulee
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/nick-jones/brulee/internal"
+	"github.com/olekukonko/tablewriter"
+)
+
+// DumpFormat selects the format in which DumpAs renders a program's instructions.
+type DumpFormat int
+
+const (
+	// DumpFormatTable renders an ASCII table, as Dump does.
+	DumpFormatTable DumpFormat = iota
+	// DumpFormatJSON renders a JSON array holding an object per instruction.
+	DumpFormatJSON
+	// DumpFormatPlain renders a line of text per instruction.
+	DumpFormatPlain
+)
+
+// Dump renders the program's instructions as an ASCII table.
+func (p Program) Dump(w io.Writer) {
+	_ = p.DumpAs(w, DumpFormatTable)
+}
+
+// DumpAs renders the program's instructions in the given format.
+func (p Program) DumpAs(w io.Writer, format DumpFormat) error {
+	switch format {
+	case DumpFormatTable:
+		p.dumpTable(w)
+		return nil
+	case DumpFormatJSON:
+		return p.dumpJSON(w)
+	case DumpFormatPlain:
+		return p.dumpPlain(w)
+	default:
+		return fmt.Errorf("unknown dump format %d", format)
+	}
+}
+
+func (p Program) dumpTable(w io.Writer) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Pos", "Op", "Ret", "Operand1", "Operand2", "Rule"})
+
+	for i, in := range p.ins {
+		s := []string{strconv.Itoa(i)}
+		s = append(s, in.StringSlice()...)
+		s = append(s, in.Rule)
+		table.Append(s)
+	}
+
+	table.Render()
+}
+
+func (p Program) dumpPlain(w io.Writer) error {
+	for i, in := range p.ins {
+		if _, err := fmt.Fprintln(w, plainInstruction(i, in)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func plainInstruction(i int, in internal.Instruction) string {
+	parts := []string{strconv.Itoa(i)}
+	for _, s := range in.StringSlice() {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if in.Pos.Line > 0 {
+		parts = append(parts, fmt.Sprintf("at %d:%d", in.Pos.Line, in.Pos.Column))
+	}
+	if in.Rule != "" {
+		parts = append(parts, fmt.Sprintf("in rule %q", in.Rule))
+	}
+	return strings.Join(parts, " ")
+}
+
+type jsonInstruction struct {
+	Pos      int          `json:"pos"`
+	Op       string       `json:"op"`
+	Ret      int          `json:"ret,omitempty"`
+	Operand1 *jsonOperand `json:"operand1,omitempty"`
+	Operand2 *jsonOperand `json:"operand2,omitempty"`
+	Source   *jsonSource  `json:"source,omitempty"`
+	Rule     string       `json:"rule,omitempty"`
+}
+
+type jsonOperand struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+type jsonSource struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p Program) dumpJSON(w io.Writer) error {
+	ins := make([]jsonInstruction, len(p.ins))
+	for i, in := range p.ins {
+		ins[i] = jsonInstruction{
+			Pos:      i,
+			Op:       in.Operation.String(),
+			Ret:      int(in.Ret),
+			Operand1: newJSONOperand(in.Operand1),
+			Operand2: newJSONOperand(in.Operand2),
+			Rule:     in.Rule,
+		}
+		if in.Pos.Line > 0 {
+			ins[i].Source = &jsonSource{Line: in.Pos.Line, Column: in.Pos.Column}
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ins)
+}
+
+func newJSONOperand(op internal.Operand) *jsonOperand {
+	switch o := op.(type) {
+	case internal.RegexpOperand:
+		return &jsonOperand{Type: "regexp", Value: o.Value.String()}
+	case internal.VarOperand:
+		return &jsonOperand{Type: "var", Value: o.Name}
+	case internal.IntOperand:
+		return &jsonOperand{Type: "int", Value: o.Value}
+	case internal.FloatOperand:
+		return &jsonOperand{Type: "float", Value: o.Value}
+	case internal.StringOperand:
+		return &jsonOperand{Type: "string", Value: o.Value}
+	case internal.ScratchOperand:
+		return &jsonOperand{Type: "scratch", Value: int(o.Pos)}
+	case internal.ScoreOperand:
+		return &jsonOperand{Type: "score", Value: o.Name}
+	case internal.InstructionPositionOperand:
+		return &jsonOperand{Type: "position", Value: o.Pos}
+	default:
+		return nil
+	}
+}
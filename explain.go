@@ -0,0 +1,44 @@
+package brulee
+
+import (
+	"github.com/nick-jones/brulee/internal"
+)
+
+// TraceEvent describes a condition evaluated, or a score change applied, whilst running a program.
+type TraceEvent = internal.TraceEvent
+
+// Contribution describes the effect a single score change had upon a score.
+type Contribution = internal.Contribution
+
+// Explanation holds the scores resulting from a run, along with the ordered trace of events that produced them.
+// Attribution holds the score changes from that trace grouped by score name, such that the contributions to each
+// score can be presented alongside it.
+type Explanation struct {
+	Scores      map[string]float64
+	Trace       []TraceEvent
+	Attribution map[string][]Contribution
+}
+
+// Explain runs the program as Run does, additionally recording each condition evaluated and score change applied.
+func (p Program) Explain(vars map[string]string) (Explanation, error) {
+	return p.explain(internal.StringVariables(vars))
+}
+
+// ExplainValues is the equivalent of Explain for typed variables.
+func (p Program) ExplainValues(vars map[string]Value) (Explanation, error) {
+	return p.explain(internal.Values(vars))
+}
+
+func (p Program) explain(vars internal.Variables) (Explanation, error) {
+	i, err := p.run(vars, runOptions{trace: true})
+	defer p.release(i)
+	if err != nil {
+		return Explanation{}, err
+	}
+	trace := i.Trace()
+	return Explanation{
+		Scores:      i.FloatScores(),
+		Trace:       trace,
+		Attribution: internal.Attribute(trace),
+	}, nil
+}
@@ -0,0 +1,63 @@
+Feature: Prefix and suffix operators
+
+  Scenario Outline: Prefix and suffix comparisons
+    Given the program:
+    """
+    when
+      <condition>
+    then
+      score(x) = 1
+    else
+      score(x) = 0
+    done
+    """
+    And variables:
+      | Name | Value                         |
+      | url  | https://example.com/news.html |
+    When the program is run
+    Then the score output is:
+      | Name | Score   |
+      | x    | <score> |
+
+    Examples:
+      | condition                               | score |
+      | var(url) starts with "https:"           | 1     |
+      | var(url) starts with "http:"            | 0     |
+      | var(url) does not start with "http:"    | 1     |
+      | var(url) does not start with "https:"   | 0     |
+      | var(url) ends with ".html"              | 1     |
+      | var(url) ends with ".json"              | 0     |
+      | var(url) does not end with ".json"      | 1     |
+      | var(url) does not end with ".html"      | 0     |
+      | var(url) starts with ""                 | 1     |
+
+  Scenario: Prefix of list items
+    Given the program:
+    """
+    when
+      var(skus) starts with "UK-"
+    then
+      score(x) = 1
+    done
+    """
+    And typed variables:
+      | Name | Type | Value         |
+      | skus | list | FR-001,UK-002 |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Prefix operators require strings
+    Given the invalid program:
+    """
+    when
+      score(y) ends with "0"
+    then
+      score(x) = 1
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 2:3: number operand is not valid for ends with, expected string
+    """
@@ -0,0 +1,42 @@
+Feature: Binary encoding
+
+  Scenario: Reloaded program runs as compiled
+    Given the program:
+    """
+    score(total) = 0.5
+    rule "tech" when
+      var(title) matches /(?i)golang|rust/
+      and not var(tags) in ["opinion", "sponsored"]
+    then
+      score(total) = score(total) * 2 + 3
+    else
+      score(total) -= 1
+    done
+    """
+    And the program is reloaded from binary
+    And variables:
+      | Name  | Value       |
+      | title | Golang 2.0  |
+      | tags  | news        |
+    When the program is run
+    Then the float score output is:
+      | Name  | Score |
+      | total | 4     |
+
+  Scenario: Reloaded program reports positioned errors
+    Given the program:
+    """
+    rule "weighted" when
+      var(a) == "x"
+    then
+      score(x) /= 0
+    done
+    """
+    And the program is reloaded from binary
+    And variables:
+      | Name | Value |
+      | a    | x     |
+    Then the program run fails with:
+    """
+    4:3: rule "weighted": division by zero
+    """
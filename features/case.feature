@@ -0,0 +1,84 @@
+Feature: Case-insensitive operators
+
+  Scenario Outline: Case-insensitive comparisons
+    Given the program:
+    """
+    when
+      <condition>
+    then
+      score(x) = 1
+    else
+      score(x) = 0
+    done
+    """
+    And variables:
+      | Name  | Value          |
+      | title | Brexit Deal    |
+      | topic | ÉLECTIONS      |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | <score> |
+
+    Examples:
+      | condition                                          | score |
+      | var(title) ==~ "brexit deal"                       | 1     |
+      | var(title) ==~ "BREXIT DEAL"                       | 1     |
+      | var(title) ==~ "brexit"                            | 0     |
+      | var(title) == "brexit deal"                        | 0     |
+      | var(title) contains ignoring case "DEAL"           | 1     |
+      | var(title) contains ignoring case "deals"          | 0     |
+      | var(title) does not contain ignoring case "DEAL"   | 0     |
+      | var(title) does not contain ignoring case "no"     | 1     |
+      | var(topic) in ignoring case ["eu", "élections"]    | 1     |
+      | var(topic) in ["eu", "élections"]                  | 0     |
+      | var(topic) not in ignoring case ["eu", "élections"] | 0    |
+      | var(topic) not in ignoring case ["eu", "sport"]    | 1     |
+
+  Scenario: Case-insensitive list membership
+    Given the program:
+    """
+    when
+      var(tags) contains ignoring case "UK"
+    then
+      score(x) = 1
+    done
+    """
+    And typed variables:
+      | Name | Type | Value        |
+      | tags | list | politics,uk  |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Case-insensitive operators require strings
+    Given the invalid program:
+    """
+    when
+      var(a) ==~ 10
+    then
+      score(x) = 1
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 2:14: number operand is not valid for ==~, expected string
+    """
+
+  Scenario: Literals are folded at compile time
+    Given the program:
+    """
+    when
+      var(a) contains ignoring case "Brexit"
+    then
+      score(x) = 1
+    done
+    """
+    Then the plain dump is:
+    """
+    0 CONTAINS_FOLD $1 var(a) string("brexit") at 2:3
+    1 JUMP_IF_ZERO $1 ->3 at 1:1
+    2 SET_SCORE score(x) int(1) at 4:3
+    3 NOOP
+    """
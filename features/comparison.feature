@@ -498,3 +498,105 @@ Feature:
     """
     When the program is run
     Then the score output is empty
+
+  Scenario: Variable to int greater than
+    Given the program:
+    """
+    when
+      var(age) > 18
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name | Value |
+      | age  | 21    |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Variable to int not greater than
+    Given the program:
+    """
+    when
+      var(age) > 18
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name | Value |
+      | age  | 9     |
+    When the program is run
+    Then the score output is empty
+
+  Scenario: Variable to int less than or equal, compared numerically
+    Given the program:
+    """
+    when
+      var(price) <= 100
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name  | Value |
+      | price | 20    |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Variable to int equal, compared numerically
+    Given the program:
+    """
+    when
+      var(count) == 5
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name  | Value |
+      | count | 05    |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Variable to score comparison
+    Given the program:
+    """
+    score(threshold) = 10
+    when
+      var(count) >= score(threshold)
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name  | Value |
+      | count | 10    |
+    When the program is run
+    Then the score output is:
+      | Name      | Score |
+      | threshold | 10    |
+      | x         | 1     |
+
+  Scenario: Non-numeric variable to int comparison
+    Given the program:
+    """
+    when
+      var(age) > 18
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name | Value |
+      | age  | old   |
+    Then the program run fails with:
+    """
+    2:3: could not coerce string value "old" of var(age) into number
+    """
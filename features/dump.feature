@@ -0,0 +1,111 @@
+Feature: Dump
+
+  Scenario: Plain dump
+    Given the program:
+    """
+    rule "a" when
+      var(a) == "x"
+    then
+      score(x) += 0.5
+    done
+    """
+    Then the plain dump is:
+    """
+    0 IS_EQUAL $1 var(a) string("x") at 2:3 in rule "a"
+    1 JUMP_IF_ZERO $1 ->3 at 1:1 in rule "a"
+    2 ADD_SCORE score(x) float(0.5) at 4:3 in rule "a"
+    3 NOOP
+    """
+
+  Scenario: JSON dump
+    Given the program:
+    """
+    when
+      var(a) matches /^x/
+    then
+      score(x) = 2
+    done
+    """
+    Then the JSON dump is:
+    """
+    [
+      {
+        "pos": 0,
+        "op": "MATCHES",
+        "ret": 1,
+        "operand1": {
+          "type": "var",
+          "value": "a"
+        },
+        "operand2": {
+          "type": "regexp",
+          "value": "^x"
+        },
+        "source": {
+          "line": 2,
+          "column": 3
+        }
+      },
+      {
+        "pos": 1,
+        "op": "JUMP_IF_ZERO",
+        "operand1": {
+          "type": "scratch",
+          "value": 1
+        },
+        "operand2": {
+          "type": "position",
+          "value": 3
+        },
+        "source": {
+          "line": 1,
+          "column": 1
+        }
+      },
+      {
+        "pos": 2,
+        "op": "SET_SCORE",
+        "operand1": {
+          "type": "score",
+          "value": "x"
+        },
+        "operand2": {
+          "type": "int",
+          "value": 2
+        },
+        "source": {
+          "line": 4,
+          "column": 3
+        }
+      },
+      {
+        "pos": 3,
+        "op": "NOOP"
+      }
+    ]
+    """
+
+  Scenario: Control flow graph
+    Given the program:
+    """
+    when
+      var(x) == "foo"
+    then
+      score(foo) = 1
+    else
+      exit
+    done
+    """
+    Then the graph is:
+    """
+    digraph program {
+      node [shape=box, fontname="monospace"];
+      b0 [label="0 IS_EQUAL $1 var(x) string(\"foo\") at 2:3\l1 JUMP_IF_ZERO $1 ->4 at 1:1\l"];
+      b2 [label="2 SET_SCORE score(foo) int(1) at 4:3\l3 JUMP ->5 at 1:1\l"];
+      b4 [label="4 EXIT at 6:3\l"];
+      b5 [label="5 NOOP\l"];
+      b0 -> b2 [label="true"];
+      b0 -> b4 [label="false"];
+      b2 -> b5;
+    }
+    """
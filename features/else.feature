@@ -0,0 +1,169 @@
+Feature: Else branches
+
+  Scenario: Else, condition passes
+    Given the program:
+    """
+    when
+      "x" == "x"
+    then
+      score(x) = 1
+    else
+      score(x) = 2
+    done
+    """
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Else, condition fails
+    Given the program:
+    """
+    when
+      "x" == "y"
+    then
+      score(x) = 1
+    else
+      score(x) = 2
+    done
+    """
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 2     |
+
+  Scenario: Else when, first condition passes
+    Given the program:
+    """
+    when
+      var(a) == "x"
+    then
+      score(x) = 1
+    else when
+      var(a) == "y"
+    then
+      score(x) = 2
+    else
+      score(x) = 3
+    done
+    """
+    And variables:
+      | Name | Value |
+      | a    | x     |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Else when, second condition passes
+    Given the program:
+    """
+    when
+      var(a) == "x"
+    then
+      score(x) = 1
+    else when
+      var(a) == "y"
+    then
+      score(x) = 2
+    else
+      score(x) = 3
+    done
+    """
+    And variables:
+      | Name | Value |
+      | a    | y     |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 2     |
+
+  Scenario: Else when, no conditions pass
+    Given the program:
+    """
+    when
+      var(a) == "x"
+    then
+      score(x) = 1
+    else when
+      var(a) == "y"
+    then
+      score(x) = 2
+    else
+      score(x) = 3
+    done
+    """
+    And variables:
+      | Name | Value |
+      | a    | z     |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 3     |
+
+  Scenario: Else when without else, no conditions pass
+    Given the program:
+    """
+    when
+      var(a) == "x"
+    then
+      score(x) = 1
+    else when
+      var(a) == "y"
+    then
+      score(x) = 2
+    done
+    """
+    And variables:
+      | Name | Value |
+      | a    | z     |
+    When the program is run
+    Then the score output is empty
+
+  Scenario: Else when, only the first passing branch runs
+    Given the program:
+    """
+    when
+      "x" == "y"
+    then
+      score(x) += 1
+    else when
+      "x" == "x"
+    then
+      score(x) += 2
+    else when
+      "y" == "y"
+    then
+      score(x) += 4
+    else
+      score(x) += 8
+    done
+    """
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 2     |
+
+  Scenario: Nested rule within else
+    Given the program:
+    """
+    when
+      "x" == "y"
+    then
+      score(x) = 1
+    else
+      score(x) = 2
+      when
+        "y" == "y"
+      then
+        score(y) = 1
+      else
+        score(y) = 2
+      done
+    done
+    """
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 2     |
+      | y    | 1     |
@@ -0,0 +1,73 @@
+Feature: Error positions
+
+  Scenario: Parse error
+    Given the invalid program:
+    """
+    when
+      var(a) == "x"
+    then
+      score(x) = 1
+    """
+    Then the program fails to compile at 4:15
+
+  Scenario: Invalid regexp
+    Given the invalid program:
+    """
+    score(x) = 1
+    when
+      var(a) == "x"
+      and var(b) matches /(/
+    then
+      score(x) = 1
+    done
+    """
+    Then the program fails to compile at 4:22
+
+  Scenario: Runtime coercion error
+    Given the program:
+    """
+    score(x) = 1
+    when
+      var(a) == "x"
+      or var(b) > 10
+    then
+      score(x) = 2
+    done
+    """
+    And variables:
+      | Name | Value |
+      | a    | y     |
+      | b    | abc   |
+    Then the program run fails at 4:6
+
+  Scenario: Runtime arithmetic error
+    Given the program:
+    """
+    score(x) = 10
+    score(y) = score(x) +
+      score(x) / score(z)
+    """
+    Then the program run fails at 3:12
+
+  Scenario: All compile errors are reported
+    Given the invalid program:
+    """
+    when
+      var(a) matches /(/
+    then
+      score(x) = 1
+    done
+    when
+      score(x) contains "y"
+      or var(b) in ["x", /[/]
+    then
+      score(x) = 1
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 2:18: failed to map second operand: regex compile failed: error parsing regexp: missing closing ): `(`
+    7:3: number operand is not valid for contains, expected string
+    8:22: regexp operand is not valid for in
+    8:22: failed to list value operand: regex compile failed: error parsing regexp: missing closing ]: `[`
+    """
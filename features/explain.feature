@@ -0,0 +1,84 @@
+Feature: Explain
+
+  Scenario: Trace of conditions and score changes
+    Given the program:
+    """
+    score(politics) = 0
+    when
+      var(title) contains "labour"
+      or var(title) contains "brexit"
+    then
+      score(politics) += 10
+    done
+    when
+      var(topic) in ["eu", "elections"]
+    then
+      score(politics) += 5
+    done
+    """
+    And variables:
+      | Name  | Value            |
+      | title | brexit deal      |
+      | topic | sport            |
+    When the program is explained
+    Then the trace is:
+    """
+    1:1: SET_SCORE score(politics) int(0): 0 -> 0
+    3:3: CONTAINS var(title) string("labour"): false
+    4:6: CONTAINS var(title) string("brexit"): true
+    6:3: ADD_SCORE score(politics) int(10): 0 -> 10
+    9:3: IS_EQUAL var(topic) string("eu"): false
+    9:3: IS_EQUAL var(topic) string("elections"): false
+    """
+
+  Scenario: Trace stops at exit
+    Given the program:
+    """
+    when
+      "x" == "x"
+    then
+      score(x) = 0.5
+      exit
+    done
+    score(x) = 2
+    """
+    When the program is explained
+    Then the trace is:
+    """
+    2:3: IS_EQUAL string("x") string("x"): true
+    4:3: SET_SCORE score(x) float(0.5): 0 -> 0.5
+    """
+    And the float score output is:
+      | Name | Score |
+      | x    | 0.5   |
+
+  Scenario: Attribution of score changes
+    Given the program:
+    """
+    score(politics) = 0
+    when
+      var(title) contains "brexit"
+    then
+      score(politics) += 10
+      score(sport) -= 2
+    done
+    when
+      var(topic) == "eu"
+    then
+      score(politics) += 5
+    done
+    when
+      var(topic) == "football"
+    then
+      score(sport) += 20
+    done
+    """
+    And variables:
+      | Name  | Value       |
+      | title | brexit deal |
+      | topic | eu          |
+    When the program is explained
+    Then the attribution is:
+      | Name     | Contributions                                    |
+      | politics | =0 from line 1, +10 from line 5, +5 from line 11 |
+      | sport    | -2 from line 6                                   |
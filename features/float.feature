@@ -0,0 +1,97 @@
+Feature: Float scores
+
+  Scenario: Float score adjustment
+    Given the program:
+    """
+    score(relevance) = 1
+    score(relevance) += 0.35
+    score(relevance) *= 2
+    """
+    When the program is run
+    Then the float score output is:
+      | Name      | Score |
+      | relevance | 2.7   |
+
+  Scenario: Float scores are truncated in int output
+    Given the program:
+    """
+    score(relevance) = 2.7
+    score(other) = -1.5
+    """
+    When the program is run
+    Then the score output is:
+      | Name      | Score |
+      | relevance | 2     |
+      | other     | -1    |
+
+  Scenario: Int division is retained without floats
+    Given the program:
+    """
+    score(x) = 7 / 2
+    score(y) = 7 / 2.0
+    """
+    When the program is run
+    Then the float score output is:
+      | Name | Score |
+      | x    | 3     |
+      | y    | 3.5   |
+
+  Scenario: Float arithmetic
+    Given the program:
+    """
+    score(a) = 0.5
+    score(x) = score(a) * 3 - 0.25
+    score(y) = score(a)-0.25
+    """
+    When the program is run
+    Then the float score output is:
+      | Name | Score |
+      | a    | 0.5   |
+      | x    | 1.25  |
+      | y    | 0.25  |
+
+  Scenario: Float comparison
+    Given the program:
+    """
+    score(a) = 0.5
+    when
+      score(a) > 0.25 and score(a) < 1 and score(a) == 0.5
+    then
+      score(x) = 1
+    done
+    """
+    When the program is run
+    Then the float score output is:
+      | Name | Score |
+      | a    | 0.5   |
+      | x    | 1     |
+
+  Scenario: Float variable
+    Given the program:
+    """
+    when
+      var(weight) >= 0.5
+    then
+      score(x) += var(weight)
+    done
+    """
+    And variables:
+      | Name   | Value |
+      | weight | 0.75  |
+    When the program is run
+    Then the float score output is:
+      | Name | Score |
+      | x    | 0.75  |
+
+  Scenario: Typed float variable
+    Given the program:
+    """
+    score(x) = var(weight) * 2
+    """
+    And typed variables:
+      | Name   | Type  | Value |
+      | weight | float | 0.2   |
+    When the program is run
+    Then the float score output is:
+      | Name | Score |
+      | x    | 0.4   |
@@ -0,0 +1,136 @@
+Feature: Input schema
+
+  Scenario: Declared variables
+    Given the program:
+    """
+    input { title: string, views: int, tags: list, ratio: float, premium: bool }
+
+    when
+      var(title) contains "election"
+      and var(views) > 100
+      and var(tags) contains "politics"
+      and var(premium) == "true"
+    then
+      score(x) = var(views) * var(ratio)
+    done
+    """
+    And typed variables:
+      | Name    | Type   | Value           |
+      | title   | string | election result |
+      | views   | int    | 200             |
+      | tags    | list   | politics,uk     |
+      | ratio   | float  | 0.5             |
+      | premium | bool   | true            |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 100   |
+
+  Scenario: Declared variables supplied as strings
+    Given the program:
+    """
+    input {
+      title: string,
+      views: int
+    }
+    when
+      var(views) >= 100
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name  | Value |
+      | views | 150   |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Variables not matching the input block
+    Given the program:
+    """
+    input { title: string, views: int }
+    when
+      var(views) >= 100
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name  | Value |
+      | views | many  |
+    Then the program run fails with:
+    """
+    1:24: variable views expects int, got string value "many"
+    """
+
+  Scenario: Bools supplied as strings
+    Given the program:
+    """
+    input { flag: bool }
+    when
+      var(flag) == "true"
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name | Value |
+      | flag | 1     |
+    Then the program run fails with:
+    """
+    1:9: variable flag expects bool, got string value "1"
+    """
+
+  Scenario: Input block retained in binary
+    Given the program:
+    """
+    input { tags: list }
+    when
+      var(tags) contains "uk"
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name | Value |
+      | tags | uk    |
+    And the program is reloaded from binary
+    Then the program run fails with:
+    """
+    1:9: variable tags expects list, got string value "uk"
+    """
+
+  Scenario: References not matching the input block
+    Given the invalid program:
+    """
+    input { title: string, views: int, tags: list, title: int }
+    when
+      var(tilte) contains "x"
+      or var(views) contains "1"
+      or var(tags) == "uk"
+      or var(title) > 1
+    then
+      score(x) = var(title) + var(views)
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 1:48: duplicate variable title, previously declared at 1:9
+    3:3: undeclared variable tilte
+    4:6: number operand is not valid for contains, expected string
+    5:6: list operand is not valid for ==
+    6:6: string operand is not valid for >, expected number
+    8:14: string operand is not valid for =, expected number
+    """
+
+  Scenario: Unknown variable type
+    Given the invalid program:
+    """
+    input { title: text }
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 1:9: unknown type text for variable title
+    """
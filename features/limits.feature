@@ -0,0 +1,38 @@
+Feature: Limits
+
+  Scenario: Run within the instruction limit
+    Given the program:
+    """
+    when
+      var(a) == "x"
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name | Value |
+      | a    | x     |
+    And runs are limited to 4 instructions
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Run exceeding the instruction limit
+    Given the program:
+    """
+    when
+      var(a) == "x"
+    then
+      score(x) = 1
+      score(y) = 2
+    done
+    """
+    And variables:
+      | Name | Value |
+      | a    | x     |
+    And runs are limited to 3 instructions
+    Then the program run fails with:
+    """
+    5:3: instruction limit exceeded
+    """
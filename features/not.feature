@@ -0,0 +1,120 @@
+Feature: Not operator
+
+  Scenario: Not, condition passes
+    Given the program:
+    """
+    when
+      not "x" == "x"
+    then
+      score(x) = 1
+    done
+    """
+    When the program is run
+    Then the score output is empty
+
+  Scenario: Not, condition fails
+    Given the program:
+    """
+    when
+      not "x" == "y"
+    then
+      score(x) = 1
+    done
+    """
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Not, grouped expression
+    Given the program:
+    """
+    when
+      not (var(a) == "x" and var(b) == "y")
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name | Value |
+      | a    | x     |
+      | b    | z     |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Not, grouped expression failing
+    Given the program:
+    """
+    when
+      not (var(a) == "x" and var(b) == "y")
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name | Value |
+      | a    | x     |
+      | b    | y     |
+    When the program is run
+    Then the score output is empty
+
+  Scenario: Not binds tighter than and
+    Given the program:
+    """
+    when
+      not "x" == "y" and "y" == "y"
+    then
+      score(x) = 1
+    done
+    """
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Not within or
+    Given the program:
+    """
+    when
+      "x" == "y" or not "y" == "z"
+    then
+      score(x) = 1
+    done
+    """
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Double not
+    Given the program:
+    """
+    when
+      not not "x" == "x"
+    then
+      score(x) = 1
+    done
+    """
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Not with list condition
+    Given the program:
+    """
+    when
+      not var(a) not in ["x", "y"]
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name | Value |
+      | a    | x     |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
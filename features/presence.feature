@@ -0,0 +1,73 @@
+Feature: Variable presence
+
+  Scenario Outline: Presence checks
+    Given the program:
+    """
+    when
+      <condition>
+    then
+      score(x) = 1
+    else
+      score(x) = 0
+    done
+    """
+    And variables:
+      | Name   | Value  |
+      | author | alice  |
+      | byline |        |
+    When the program is run
+    Then the score output is:
+      | Name | Score   |
+      | x    | <score> |
+
+    Examples:
+      | condition                                 | score |
+      | var(author) is set                        | 1     |
+      | var(byline) is set                        | 1     |
+      | var(source) is set                        | 0     |
+      | var(author) is not set                    | 0     |
+      | var(source) is not set                    | 1     |
+      | var(byline) is empty                      | 1     |
+      | var(author) is empty                      | 0     |
+      | var(source) is empty                      | 0     |
+      | var(source) is not set and var(byline) is empty | 1 |
+      | not var(author) is set or var(author) == "alice" | 1 |
+
+  Scenario: Empty lists
+    Given the program:
+    """
+    when
+      var(tags) is empty
+    then
+      score(x) = 1
+    done
+    when
+      var(count) is empty
+    then
+      score(y) = 1
+    done
+    """
+    And typed variables:
+      | Name  | Type | Value |
+      | tags  | list |       |
+      | count | int  | 0     |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Presence checks in traces
+    Given the program:
+    """
+    when
+      var(author) is not set
+    then
+      score(syndicated) = 1
+    done
+    """
+    When the program is explained
+    Then the trace is:
+    """
+    2:3: IS_NOT_SET var(author): true
+    4:3: SET_SCORE score(syndicated) int(1): 0 -> 1
+    """
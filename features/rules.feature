@@ -0,0 +1,136 @@
+Feature: Named rules
+
+  Scenario: Named rules are listed and scored
+    Given the program:
+    """
+    rule "uk_politics"
+    when
+      var(title) contains "brexit"
+    then
+      score(politics) += 10
+      rule "eu_politics"
+      when
+        var(title) contains "eu"
+      then
+        score(politics) += 5
+      done
+    done
+    when
+      var(title) contains "deal"
+    then
+      score(politics) += 1
+    done
+    rule "sport"
+    when
+      var(title) contains "football"
+    then
+      score(sport) = 10
+    done
+    """
+    And variables:
+      | Name  | Value          |
+      | title | brexit eu deal |
+    When the program is run
+    Then the score output is:
+      | Name     | Score |
+      | politics | 16    |
+    And the rules are:
+      | Name        |
+      | uk_politics |
+      | eu_politics |
+      | sport       |
+
+  Scenario: Rule names in traces and attribution
+    Given the program:
+    """
+    score(politics) = 0
+    rule "uk_politics" when
+      var(title) contains "brexit"
+    then
+      score(politics) += 10
+    done
+    """
+    And variables:
+      | Name  | Value       |
+      | title | brexit deal |
+    When the program is explained
+    Then the trace is:
+    """
+    1:1: SET_SCORE score(politics) int(0): 0 -> 0
+    3:3: rule "uk_politics": CONTAINS var(title) string("brexit"): true
+    5:3: rule "uk_politics": ADD_SCORE score(politics) int(10): 0 -> 10
+    """
+    And the attribution is:
+      | Name     | Contributions                                                 |
+      | politics | =0 from line 1, +10 from rule "uk_politics" at line 5         |
+
+  Scenario: Rule names in runtime errors
+    Given the program:
+    """
+    rule "weighted" when
+      var(a) == "x"
+    then
+      score(x) = 1
+      score(x) /= 0
+    done
+    """
+    And variables:
+      | Name | Value |
+      | a    | x     |
+    Then the program run fails with:
+    """
+    5:3: rule "weighted": division by zero
+    """
+
+  Scenario: Rule names in compile errors
+    Given the invalid program:
+    """
+    rule "broken" when
+      var(a) matches "x"
+    then
+      score(x) = 1
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 2:18: rule "broken": string operand is not valid for matches, expected regexp
+    """
+
+  Scenario: Duplicate rule names
+    Given the invalid program:
+    """
+    rule "a" when
+      var(a) == "x"
+    then
+      score(x) = 1
+    done
+    rule "a" when
+      var(a) == "y"
+    then
+      score(x) = 2
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 6:1: rule "a": duplicate rule name, previously declared at 1:1
+    """
+
+  Scenario: Empty rule names
+    Given the invalid program:
+    """
+    rule "" when
+      var(a) == "x"
+    then
+      score(x) = 1
+    done
+    rule "" when
+      var(a) == "y"
+    then
+      score(x) = 2
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 1:1: rule name must not be empty
+    6:1: rule name must not be empty
+    """
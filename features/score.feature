@@ -16,3 +16,144 @@ Feature:
 			| x    | 2     |
 			| y    | 9     |
 			| z    | 10    |
+
+	Scenario: Score arithmetic
+		Given the program:
+    """
+    score(a) = 10
+    score(b) = 9
+    score(total) = score(a) * 2 + score(b) / 3 - 1
+    """
+		When the program is run
+		Then the score output is:
+			| Name  | Score |
+			| a     | 10    |
+			| b     | 9     |
+			| total | 22    |
+
+	Scenario: Score arithmetic with parentheses
+		Given the program:
+    """
+    score(a) = 2
+    score(x) = (score(a) + 1) * (score(a) - 4)
+    score(y) = 20 / (2 * (1 + 1))
+    """
+		When the program is run
+		Then the score output is:
+			| Name | Score |
+			| a    | 2     |
+			| x    | -6    |
+			| y    | 5     |
+
+	Scenario: Score arithmetic applied as an adjustment
+		Given the program:
+    """
+    score(a) = 3
+    score(x) = 1
+    score(x) += score(a) * 2
+    score(x) -= 1 + 1
+    """
+		When the program is run
+		Then the score output is:
+			| Name | Score |
+			| a    | 3     |
+			| x    | 5     |
+
+	Scenario: Score arithmetic with variables
+		Given the program:
+    """
+    score(x) = var(views) / 100
+    """
+		And variables:
+			| Name  | Value |
+			| views | 1234  |
+		When the program is run
+		Then the score output is:
+			| Name | Score |
+			| x    | 12    |
+
+	Scenario: Score arithmetic division by zero
+		Given the program:
+    """
+    score(x) = 1 / score(y)
+    """
+		Then the program run fails with:
+    """
+    1:14: division by zero
+    """
+
+	Scenario: Score arithmetic without whitespace
+		Given the program:
+    """
+    score(a) = 4
+    score(x) = score(a)-1+6/2
+    score(y) = 2*-3
+    """
+		When the program is run
+		Then the score output is:
+			| Name | Score |
+			| a    | 4     |
+			| x    | 6     |
+			| y    | -6    |
+
+	Scenario: Score arithmetic alongside regexps
+		Given the program:
+    """
+    score(a) = 8 / 2
+    when
+      score(a) > 3 and "abc" matches /b/
+    then
+      score(x) = score(a) / 2
+    done
+    """
+		When the program is run
+		Then the score output is:
+			| Name | Score |
+			| a    | 4     |
+			| x    | 2     |
+
+	Scenario: Multiplicative score adjustment
+		Given the program:
+    """
+    score(x) = 5
+    score(x) *= 2
+    score(y) = 7
+    score(y) /= 2
+    score(z) = 7
+    score(z) %= 4
+    """
+		When the program is run
+		Then the score output is:
+			| Name | Score |
+			| x    | 10    |
+			| y    | 3     |
+			| z    | 3     |
+
+	Scenario: Multiplicative score adjustment within a rule
+		Given the program:
+    """
+    score(x) = 5
+    when
+      var(source) == "trusted"
+    then
+      score(x) *= 2
+    done
+    """
+		And variables:
+			| Name   | Value   |
+			| source | trusted |
+		When the program is run
+		Then the score output is:
+			| Name | Score |
+			| x    | 10    |
+
+	Scenario: Score division by zero
+		Given the program:
+    """
+    score(x) = 5
+    score(x) /= score(y)
+    """
+		Then the program run fails with:
+    """
+    2:1: division by zero
+    """
@@ -0,0 +1,154 @@
+Feature: Strict mode
+
+  Scenario: Strict run with defined variables and scores
+    Given the program:
+    """
+    score(total) = 0
+    when
+      var(title) contains "election"
+    then
+      score(total) += var(views) / 100
+    done
+    """
+    And variables:
+      | Name  | Value           |
+      | title | election result |
+      | views | 500             |
+    And runs are strict
+    When the program is run
+    Then the score output is:
+      | Name  | Score |
+      | total | 5     |
+
+  Scenario: Undefined variables read as empty without strict mode
+    Given the program:
+    """
+    when
+      var(tilte) == ""
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name  | Value           |
+      | title | election result |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Undefined variable in strict mode
+    Given the program:
+    """
+    when
+      var(tilte) contains "election"
+    then
+      score(x) = 1
+    done
+    """
+    And variables:
+      | Name  | Value           |
+      | title | election result |
+    And runs are strict
+    Then the program run fails with:
+    """
+    2:3: undefined variable tilte
+    """
+
+  Scenario: Undefined score in strict mode
+    Given the program:
+    """
+    when
+      var(title) contains "election"
+    then
+      score(poltics) += 10
+    done
+    """
+    And variables:
+      | Name  | Value           |
+      | title | election result |
+    And runs are strict
+    Then the program run fails with:
+    """
+    4:3: undefined score poltics
+    """
+
+  Scenario: Presence conditions in strict mode
+    Given the program:
+    """
+    when
+      var(author) is not set
+    then
+      score(syndicated) = 1
+    done
+    """
+    And runs are strict
+    When the program is run
+    Then the score output is:
+      | Name       | Score |
+      | syndicated | 1     |
+
+  Scenario: Declared variables
+    Given the declared variables are:
+      | Name  |
+      | title |
+      | views |
+    And the program:
+    """
+    when
+      var(title) contains "election" and var(views) > 100
+    then
+      score(x) = var(views)
+    done
+    """
+    And variables:
+      | Name  | Value    |
+      | title | election |
+      | views | 200      |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 200   |
+
+  Scenario: No declared variables
+    Given the declared variables are:
+      | Name |
+    And the invalid program:
+    """
+    when
+      var(tilte) contains "election"
+    then
+      score(x) = 1
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 2:3: undeclared variable tilte
+    """
+
+  Scenario: Undeclared variables
+    Given the declared variables are:
+      | Name  |
+      | title |
+      | tags  |
+    And the invalid program:
+    """
+    rule "politics"
+    when
+      var(tilte) contains "election" or "uk" in [var(tag), var(tags)]
+    then
+      score(x) = var(views) * 2
+    done
+    when
+      var(author) is set
+    then
+      score(y) = 1
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 3:3: rule "politics": undeclared variable tilte
+    3:46: rule "politics": undeclared variable tag
+    5:14: rule "politics": undeclared variable views
+    8:3: undeclared variable author
+    """
@@ -0,0 +1,104 @@
+Feature: Type checking
+
+  Scenario: Matches against a string
+    Given the invalid program:
+    """
+    when
+      var(a) matches "x"
+    then
+      score(x) = 1
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 2:18: string operand is not valid for matches, expected regexp
+    """
+
+  Scenario: Does not match against a var
+    Given the invalid program:
+    """
+    when
+      "abc" does not match var(a)
+    then
+      score(x) = 1
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 2:24: any operand is not valid for does not match, expected regexp
+    """
+
+  Scenario: Score contains a string
+    Given the invalid program:
+    """
+    when
+      score(x) contains "y"
+    then
+      score(x) = 1
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 2:3: number operand is not valid for contains, expected string
+    """
+
+  Scenario: String compared numerically
+    Given the invalid program:
+    """
+    when
+      var(a) > "10"
+    then
+      score(x) = 1
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 2:12: string operand is not valid for >, expected number
+    """
+
+  Scenario: String equal to an int
+    Given the invalid program:
+    """
+    when
+      "1" == 1
+    then
+      score(x) = 1
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 2:10: number operand is not valid for == against string
+    """
+
+  Scenario: Regexp in a list
+    Given the invalid program:
+    """
+    when
+      var(a) not in ["x", /y/]
+    then
+      score(x) = 1
+    done
+    """
+    Then the program fails to compile with:
+    """
+    compile failure: 2:23: regexp operand is not valid for not in
+    """
+
+  Scenario: Error within a rarely taken branch
+    Given the invalid program:
+    """
+    when
+      "x" == "y"
+    then
+      score(x) = 1
+    else when
+      "x" == "z"
+    then
+      when
+        not score(y) matches /1/
+      then
+        score(x) = 2
+      done
+    done
+    """
+    Then the program fails to compile at 9:9
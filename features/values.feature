@@ -0,0 +1,182 @@
+Feature: Typed variables
+
+  Scenario: String variable
+    Given the program:
+    """
+    when
+      var(a) == "x"
+    then
+      score(x) = 1
+    done
+    """
+    And typed variables:
+      | Name | Type   | Value |
+      | a    | string | x     |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Int variable compared to int
+    Given the program:
+    """
+    when
+      var(age) >= 18 and var(age) == 21
+    then
+      score(x) = 1
+    done
+    """
+    And typed variables:
+      | Name | Type | Value |
+      | age  | int  | 21    |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Int variable compared to string
+    Given the program:
+    """
+    when
+      var(age) == "21"
+    then
+      score(x) = 1
+    done
+    """
+    And typed variables:
+      | Name | Type | Value |
+      | age  | int  | 21    |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: Float variable compared to int
+    Given the program:
+    """
+    when
+      var(price) > 10
+    then
+      score(x) = 1
+    done
+    when
+      var(price) < 11
+    then
+      score(y) = 1
+    done
+    """
+    And typed variables:
+      | Name  | Type  | Value |
+      | price | float | 10.5  |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+      | y    | 1     |
+
+  Scenario: Bool variable
+    Given the program:
+    """
+    when
+      var(trusted) == "true"
+    then
+      score(x) = 1
+    done
+    """
+    And typed variables:
+      | Name    | Type | Value |
+      | trusted | bool | true  |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: List variable contains
+    Given the program:
+    """
+    when
+      var(tags) contains "eu"
+    then
+      score(x) = 1
+    done
+    when
+      var(tags) contains "e"
+    then
+      score(y) = 1
+    done
+    """
+    And typed variables:
+      | Name | Type | Value           |
+      | tags | list | politics,eu,uk  |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: List variable does not contain
+    Given the program:
+    """
+    when
+      var(tags) does not contain "sport"
+    then
+      score(x) = 1
+    done
+    """
+    And typed variables:
+      | Name | Type | Value       |
+      | tags | list | politics,eu |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: List variable matches
+    Given the program:
+    """
+    when
+      var(tags) matches /^pol/
+    then
+      score(x) = 1
+    done
+    """
+    And typed variables:
+      | Name | Type | Value       |
+      | tags | list | eu,politics |
+    When the program is run
+    Then the score output is:
+      | Name | Score |
+      | x    | 1     |
+
+  Scenario: List variable compared to string
+    Given the program:
+    """
+    when
+      var(tags) == "eu"
+    then
+      score(x) = 1
+    done
+    """
+    And typed variables:
+      | Name | Type | Value |
+      | tags | list | eu    |
+    Then the program run fails with:
+    """
+    2:3: could not coerce list value of var(tags) into string
+    """
+
+  Scenario: Bool variable compared to int
+    Given the program:
+    """
+    when
+      var(trusted) > 1
+    then
+      score(x) = 1
+    done
+    """
+    And typed variables:
+      | Name    | Type | Value |
+      | trusted | bool | true  |
+    Then the program run fails with:
+    """
+    2:3: could not coerce bool value "true" of var(trusted) into number
+    """
@@ -0,0 +1,15 @@
+package brulee
+
+import (
+	"github.com/nick-jones/brulee/internal"
+	"github.com/pkg/errors"
+)
+
+// Format returns the source of a program in canonical form, retaining its comments.
+func Format(src []byte) ([]byte, error) {
+	out, err := internal.Format(src)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse failure")
+	}
+	return out, nil
+}
@@ -0,0 +1,42 @@
+package brulee
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nick-jones/brulee/internal"
+)
+
+var edgeKindToLabelMap = map[internal.EdgeKind]string{
+	internal.EdgeTrue:  ` [label="true"]`,
+	internal.EdgeFalse: ` [label="false"]`,
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// DumpGraph renders the program's control flow graph in Graphviz DOT format. Each node is a basic block of
+// instructions, with edges representing jumps and fall-throughs between them. Edges from conditional jumps are
+// labelled with the value of the condition under which they are followed.
+func (p Program) DumpGraph(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph program {")
+	fmt.Fprintln(bw, `  node [shape=box, fontname="monospace"];`)
+	blocks := internal.BasicBlocks(p.ins)
+	for _, b := range blocks {
+		var label strings.Builder
+		for i := b.Start; i < b.End; i++ {
+			label.WriteString(dotEscaper.Replace(plainInstruction(i, p.ins[i])))
+			label.WriteString(`\l`)
+		}
+		fmt.Fprintf(bw, "  b%d [label=\"%s\"];\n", b.Start, label.String())
+	}
+	for _, b := range blocks {
+		for _, e := range b.Edges {
+			fmt.Fprintf(bw, "  b%d -> b%d%s;\n", b.Start, e.To, edgeKindToLabelMap[e.Kind])
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
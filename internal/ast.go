@@ -2,57 +2,113 @@
 // nolint:govet
 package internal
 
+import (
+	"github.com/alecthomas/participle/lexer"
+)
+
 type Root struct {
+	Input      *Input      `[ @@ ]`
 	Statements []Statement `@@*`
 }
 
+type Input struct {
+	Pos lexer.Position
+
+	Fields []InputField `"input" "{" { @@ [ "," ] } "}"`
+}
+
+type InputField struct {
+	Pos lexer.Position
+
+	Name string `@Ident ":"`
+	Type string `@Ident`
+}
+
 type Statement struct {
+	Pos lexer.Position
+
 	Rule        *Rule        `@@`
 	ScoreChange *ScoreChange `| @@`
 	Exit        bool         `| @( "exit" )`
 }
 
 type Rule struct {
-	Expression   Expression   `"when" @@`
-	Consequences Consequences `"then" @@ "done"`
+	Pos lexer.Position
+
+	Name         *string       `[ "rule" @String ]`
+	Expression   Expression    `"when" @@`
+	Consequences Consequences  `"then" @@`
+	ElseWhens    []ElseWhen    `{ @@ }`
+	Else         *Consequences `[ "else" @@ ] "done"`
+}
+
+type ElseWhen struct {
+	Pos lexer.Position
+
+	Expression   Expression   `"else" "when" @@`
+	Consequences Consequences `"then" @@`
 }
 
 type Expression struct {
+	Pos lexer.Position
+
 	Or []OrExpression `@@ { "or" @@ }`
 }
 
 type OrExpression struct {
+	Pos lexer.Position
+
 	And []ConditionOrExpression `@@ { "and" @@ }`
 }
 
 type ConditionOrExpression struct {
-	Condition  *Condition  `@@ `
-	Expression *Expression `| "(" @@ ")"`
+	Pos lexer.Position
+
+	Condition  *Condition             `@@ `
+	Expression *Expression            `| "(" @@ ")"`
+	Not        *ConditionOrExpression `| "not" @@`
 }
 
 type Condition struct {
-	ScalarCondition *ScalarCondition `@@`
-	ListCondition   *ListCondition   `| @@`
+	Pos lexer.Position
+
+	ScalarCondition   *ScalarCondition   `@@`
+	ListCondition     *ListCondition     `| @@`
+	PresenceCondition *PresenceCondition `| @@`
 }
 
 type ScalarCondition struct {
+	Pos lexer.Position
+
 	LeftValue  MixedValue `@@`
-	Op         string     `@( "<" { "=" } | ">" { "=" } | "=" "=" | "!" "=" | "contains" | "matches" | "does" "not" ( "match" | "contain" ) )`
+	Op         string     `@( "<" { "=" } | ">" { "=" } | "=" "=" [ "~" ] | "!" "=" | "contains" [ "ignoring" "case" ] | "matches" | "starts" "with" | "ends" "with" | "does" "not" ( "match" | "contain" [ "ignoring" "case" ] | "start" "with" | "end" "with" ) )`
 	RightValue MixedValue `@@`
 }
 
 type ListCondition struct {
+	Pos lexer.Position
+
 	LeftValue   MixedValue   `@@`
-	Op          string       `@( { "not" } "in" )`
+	Op          string       `@( { "not" } "in" [ "ignoring" "case" ] )`
 	RightValues []MixedValue `"[" @@ { "," @@ } "]"`
 }
 
+type PresenceCondition struct {
+	Pos lexer.Position
+
+	Var string `"var" "(" @Ident ")"`
+	Op  string `"is" @( "set" | "not" "set" | "empty" )`
+}
+
 type MixedValue struct {
-	Var    *string `"var" "(" @Ident ")"`
-	String *string `| @String`
-	Int    *int    `| @Int`
-	Score  *Score  `| @@`
-	Regexp *string `| @Regexp`
+	Pos lexer.Position
+
+	Var    *string  `"var" "(" @Ident ")"`
+	String *string  `| @String`
+	Int    *int     `| @Int`
+	Float  *float64 `| @Float`
+	Score  *Score   `| @@`
+	Regexp *string  `| @Regexp`
 }
 
 type Consequences struct {
@@ -60,16 +116,47 @@ type Consequences struct {
 }
 
 type ScoreChange struct {
-	Score    Score    `@@`
-	Operator string   `@( "=" | "+" "=" | "-" "=" )`
-	Value    IntValue `@@`
+	Pos lexer.Position
+
+	Score    Score  `@@`
+	Operator string `@( "=" | "+" "=" | "-" "=" | "*" "=" | "/" "=" | "%" "=" )`
+	Value    Sum    `@@`
 }
 
 type Score struct {
 	Name string `"score" "(" @Ident ")"`
 }
 
-type IntValue struct {
-	Int   *int   `@Int`
-	Score *Score `| @@`
+type Sum struct {
+	Left  Product        `@@`
+	Right []SumOperation `{ @@ }`
+}
+
+type SumOperation struct {
+	Pos lexer.Position
+
+	Operator string  `@( "+" | "-" )`
+	Product  Product `@@`
+}
+
+type Product struct {
+	Left  NumericValue       `@@`
+	Right []ProductOperation `{ @@ }`
+}
+
+type ProductOperation struct {
+	Pos lexer.Position
+
+	Operator string       `@( "*" | "/" )`
+	Value    NumericValue `@@`
+}
+
+type NumericValue struct {
+	Pos lexer.Position
+
+	Int   *int     `@Int`
+	Float *float64 `| @Float`
+	Score *Score   `| @@`
+	Var   *string  `| "var" "(" @Ident ")"`
+	Sum   *Sum     `| "(" @@ ")"`
 }
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/participle/lexer"
+)
+
+// Contribution describes the effect a single score change had upon a score.
+type Contribution struct {
+	Pos       lexer.Position
+	Rule      string
+	Operation Operation
+	// Delta holds the difference the change made to the score.
+	Delta Number
+	// Value holds the score immediately after the change.
+	Value Number
+}
+
+// String renders the contribution as e.g. "+10 from line 12", or "+10 from rule "uk_politics" at line 12" where the
+// change was made within a named rule. Assignments are rendered as "=5 from line 3", since what they replace is
+// typically of less interest than the value they assign.
+func (c Contribution) String() string {
+	source := fmt.Sprintf("line %d", c.Pos.Line)
+	if c.Rule != "" {
+		source = fmt.Sprintf("rule %q at %s", c.Rule, source)
+	}
+	if c.Operation == OperationSetScore {
+		return fmt.Sprintf("=%s from %s", c.Value, source)
+	}
+	sign := "+"
+	if c.Delta.Compare(IntNumber(0)) < 0 {
+		sign = ""
+	}
+	return fmt.Sprintf("%s%s from %s", sign, c.Delta, source)
+}
+
+// Attribute groups the score changes within a trace by score name, retaining execution order.
+func Attribute(trace []TraceEvent) map[string][]Contribution {
+	attribution := make(map[string][]Contribution)
+	for _, te := range trace {
+		if !te.IsScoreChange() {
+			continue
+		}
+		attribution[te.Score] = append(attribution[te.Score], Contribution{
+			Pos:       te.Pos,
+			Rule:      te.Rule,
+			Operation: te.Operation,
+			Delta:     te.After.Sub(te.Before),
+			Value:     te.After,
+		})
+	}
+	return attribution
+}
@@ -0,0 +1,29 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/alecthomas/participle/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttribute(t *testing.T) {
+	trace := []TraceEvent{
+		{Pos: lexer.Position{Line: 1}, Operation: OperationSetScore, Score: "a", Before: IntNumber(0), After: IntNumber(2)},
+		{Pos: lexer.Position{Line: 2}, Operation: OperationIsEqual, Result: true},
+		{Pos: lexer.Position{Line: 3}, Operation: OperationAddScore, Score: "b", Before: IntNumber(0), After: IntNumber(10)},
+		{Pos: lexer.Position{Line: 4}, Operation: OperationSubScore, Score: "a", Before: IntNumber(2), After: FloatNumber(1.5)},
+	}
+	attribution := Attribute(trace)
+	assert.Len(t, attribution, 2)
+
+	var a, b []string
+	for _, c := range attribution["a"] {
+		a = append(a, c.String())
+	}
+	for _, c := range attribution["b"] {
+		b = append(b, c.String())
+	}
+	assert.Equal(t, []string{"=2 from line 1", "-0.5 from line 4"}, a)
+	assert.Equal(t, []string{"+10 from line 3"}, b)
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"sort"
+)
+
+// BasicBlock is a run of instructions, from Start up to but excluding End, which is only entered at Start and only
+// left after End-1.
+type BasicBlock struct {
+	Start int
+	End   int
+	Edges []Edge
+}
+
+// EdgeKind describes the circumstances under which control passes along an Edge.
+type EdgeKind int8
+
+const (
+	// EdgeAlways is followed unconditionally, be that by jump or by falling through to the next instruction.
+	EdgeAlways EdgeKind = iota
+	// EdgeTrue is followed when the scratch position tested by a conditional jump is non-zero.
+	EdgeTrue
+	// EdgeFalse is followed when the scratch position tested by a conditional jump is zero.
+	EdgeFalse
+)
+
+// Edge is a transfer of control to the block starting at instruction To.
+type Edge struct {
+	To   int
+	Kind EdgeKind
+}
+
+// BasicBlocks partitions instructions into basic blocks, forming a control flow graph. Edges leading beyond the
+// final instruction are omitted.
+func BasicBlocks(ins []Instruction) []BasicBlock {
+	leaders := map[int]bool{0: true}
+	for n, in := range ins {
+		if target, ok := jumpTarget(in); ok {
+			leaders[target] = true
+		}
+		if isBranch(in.Operation) {
+			leaders[n+1] = true
+		}
+	}
+	var starts []int
+	for n := range leaders {
+		if n < len(ins) {
+			starts = append(starts, n)
+		}
+	}
+	sort.Ints(starts)
+
+	blocks := make([]BasicBlock, len(starts))
+	for n, start := range starts {
+		end := len(ins)
+		if n+1 < len(starts) {
+			end = starts[n+1]
+		}
+		blocks[n] = BasicBlock{Start: start, End: end, Edges: edgesFrom(ins[end-1], end, len(ins))}
+	}
+	return blocks
+}
+
+func edgesFrom(last Instruction, next, count int) []Edge {
+	var edges []Edge
+	add := func(to int, kind EdgeKind) {
+		if to < count {
+			edges = append(edges, Edge{To: to, Kind: kind})
+		}
+	}
+	target, _ := jumpTarget(last)
+	switch last.Operation {
+	case OperationJump:
+		add(target, EdgeAlways)
+	case OperationJumpIfZero:
+		add(next, EdgeTrue)
+		add(target, EdgeFalse)
+	case OperationJumpIfNotZero:
+		add(target, EdgeTrue)
+		add(next, EdgeFalse)
+	case OperationExit:
+		// No successors
+	default:
+		add(next, EdgeAlways)
+	}
+	return edges
+}
+
+func isBranch(op Operation) bool {
+	switch op {
+	case OperationJump, OperationJumpIfZero, OperationJumpIfNotZero, OperationExit:
+		return true
+	}
+	return false
+}
+
+func jumpTarget(in Instruction) (int, bool) {
+	switch in.Operation {
+	case OperationJump:
+		if o, ok := in.Operand1.(InstructionPositionOperand); ok {
+			return o.Pos, true
+		}
+	case OperationJumpIfZero, OperationJumpIfNotZero:
+		if o, ok := in.Operand2.(InstructionPositionOperand); ok {
+			return o.Pos, true
+		}
+	}
+	return 0, false
+}
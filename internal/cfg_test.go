@@ -0,0 +1,25 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasicBlocks(t *testing.T) {
+	ins := []Instruction{
+		{Operation: OperationIsEqual, Ret: 1, Operand1: VarOperand{Name: "a"}, Operand2: StringOperand{Value: "x"}},
+		{Operation: OperationJumpIfZero, Operand1: ScratchOperand{Pos: 1}, Operand2: InstructionPositionOperand{Pos: 4}},
+		{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "s"}, Operand2: IntOperand{Value: 1}},
+		{Operation: OperationJump, Operand1: InstructionPositionOperand{Pos: 5}},
+		{Operation: OperationExit},
+		{Operation: OperationNoop},
+	}
+	expected := []BasicBlock{
+		{Start: 0, End: 2, Edges: []Edge{{To: 2, Kind: EdgeTrue}, {To: 4, Kind: EdgeFalse}}},
+		{Start: 2, End: 4, Edges: []Edge{{To: 5, Kind: EdgeAlways}}},
+		{Start: 4, End: 5},
+		{Start: 5, End: 6},
+	}
+	assert.Equal(t, expected, BasicBlocks(ins))
+}
@@ -0,0 +1,309 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alecthomas/participle/lexer"
+)
+
+type operandType int8
+
+const (
+	operandTypeAny operandType = iota
+	operandTypeString
+	operandTypeNumber
+	operandTypeRegexp
+	operandTypeList
+)
+
+var operandTypeToStringMap = map[operandType]string{
+	operandTypeAny:    "any",
+	operandTypeString: "string",
+	operandTypeNumber: "number",
+	operandTypeRegexp: "regexp",
+	operandTypeList:   "list",
+}
+
+func (ot operandType) String() string {
+	return operandTypeToStringMap[ot]
+}
+
+var operatorToDisplayMap = map[string]string{
+	"doesnotcontain":             "does not contain",
+	"doesnotmatch":               "does not match",
+	"notin":                      "not in",
+	"containsignoringcase":       "contains ignoring case",
+	"doesnotcontainignoringcase": "does not contain ignoring case",
+	"inignoringcase":             "in ignoring case",
+	"notinignoringcase":          "not in ignoring case",
+	"startswith":                 "starts with",
+	"doesnotstartwith":           "does not start with",
+	"endswith":                   "ends with",
+	"doesnotendwith":             "does not end with",
+	"notset":                     "not set",
+}
+
+func displayOperator(op string) string {
+	if s, ok := operatorToDisplayMap[op]; ok {
+		return s
+	}
+	return op
+}
+
+// TypeChecker verifies that operands are compatible with the operators applied to them. Variables are typed by the
+// program's input block where it has one, and are otherwise typed only at runtime, so are considered compatible with
+// any operator other than those requiring a regexp. Rule names are also verified to be unique, and where variables
+// have been declared, references to any other variable are reported.
+type TypeChecker struct {
+	rules  map[string]lexer.Position
+	vars   map[string]bool
+	inputs map[string]InputField
+	rule   string
+	errs   ErrorList
+}
+
+func NewTypeChecker() *TypeChecker {
+	return &TypeChecker{
+		rules: map[string]lexer.Position{},
+	}
+}
+
+// DeclareVariables restricts the variables the program may reference to those named.
+func (tc *TypeChecker) DeclareVariables(names []string) {
+	tc.vars = make(map[string]bool, len(names))
+	for _, name := range names {
+		tc.vars[name] = true
+	}
+}
+
+func (tc *TypeChecker) Check(root Root) {
+	if root.Input != nil {
+		tc.checkInput(*root.Input)
+	}
+	tc.checkStatements(root.Statements)
+}
+
+func (tc *TypeChecker) checkInput(input Input) {
+	tc.inputs = make(map[string]InputField, len(input.Fields))
+	for _, f := range input.Fields {
+		if _, ok := valueKindFromString(f.Type); !ok {
+			tc.addErr(f.Pos, fmt.Errorf("unknown type %s for variable %s", f.Type, f.Name))
+		}
+		if prev, ok := tc.inputs[f.Name]; ok {
+			tc.addErr(f.Pos, fmt.Errorf("duplicate variable %s, previously declared at %d:%d", f.Name, prev.Pos.Line, prev.Pos.Column))
+			continue
+		}
+		tc.inputs[f.Name] = f
+	}
+}
+
+func (tc *TypeChecker) checkStatements(statements []Statement) {
+	for _, s := range statements {
+		switch {
+		case s.Rule != nil:
+			tc.checkRule(*s.Rule)
+		case s.ScoreChange != nil:
+			tc.checkSum(s.ScoreChange.Operator, s.ScoreChange.Value)
+		}
+	}
+}
+
+func (tc *TypeChecker) checkRule(rule Rule) {
+	if rule.Name != nil {
+		enclosing := tc.rule
+		defer func() { tc.rule = enclosing }()
+		tc.rule = *rule.Name
+		if tc.rule == "" {
+			tc.addErr(rule.Pos, errors.New("rule name must not be empty"))
+		} else if pos, ok := tc.rules[tc.rule]; ok {
+			tc.addErr(rule.Pos, fmt.Errorf("duplicate rule name, previously declared at %d:%d", pos.Line, pos.Column))
+		} else {
+			tc.rules[tc.rule] = rule.Pos
+		}
+	}
+	tc.checkExpression(rule.Expression)
+	tc.checkStatements(rule.Consequences.Consequences)
+	for _, ew := range rule.ElseWhens {
+		tc.checkExpression(ew.Expression)
+		tc.checkStatements(ew.Consequences.Consequences)
+	}
+	if rule.Else != nil {
+		tc.checkStatements(rule.Else.Consequences)
+	}
+}
+
+func (tc *TypeChecker) checkExpression(e Expression) {
+	for _, or := range e.Or {
+		for _, coe := range or.And {
+			tc.checkConditionOrExpression(coe)
+		}
+	}
+}
+
+func (tc *TypeChecker) checkConditionOrExpression(coe ConditionOrExpression) {
+	switch {
+	case coe.Condition != nil && coe.Condition.ScalarCondition != nil:
+		tc.checkScalarCondition(*coe.Condition.ScalarCondition)
+	case coe.Condition != nil && coe.Condition.ListCondition != nil:
+		tc.checkListCondition(*coe.Condition.ListCondition)
+	case coe.Condition != nil && coe.Condition.PresenceCondition != nil:
+		tc.checkVar(coe.Condition.PresenceCondition.Pos, coe.Condition.PresenceCondition.Var)
+	case coe.Expression != nil:
+		tc.checkExpression(*coe.Expression)
+	case coe.Not != nil:
+		tc.checkConditionOrExpression(*coe.Not)
+	}
+}
+
+func (tc *TypeChecker) checkScalarCondition(cond ScalarCondition) {
+	tc.checkMixedValue(cond.LeftValue)
+	tc.checkMixedValue(cond.RightValue)
+	left, right := tc.typeOfMixedValue(cond.LeftValue), tc.typeOfMixedValue(cond.RightValue)
+	switch cond.Op {
+	case "==", "!=":
+		tc.checkEquality(cond.Op, cond.LeftValue, cond.RightValue)
+	case "<", "<=", ">", ">=":
+		tc.expect(cond.Op, cond.LeftValue.Pos, left, operandTypeNumber)
+		tc.expect(cond.Op, cond.RightValue.Pos, right, operandTypeNumber)
+	case "==~":
+		tc.expect(cond.Op, cond.LeftValue.Pos, left, operandTypeString)
+		tc.expect(cond.Op, cond.RightValue.Pos, right, operandTypeString)
+	case "contains", "doesnotcontain", "containsignoringcase", "doesnotcontainignoringcase",
+		"startswith", "doesnotstartwith", "endswith", "doesnotendwith":
+		tc.expectText(cond.Op, cond.LeftValue.Pos, left)
+		tc.expect(cond.Op, cond.RightValue.Pos, right, operandTypeString)
+	case "matches", "doesnotmatch":
+		tc.expectText(cond.Op, cond.LeftValue.Pos, left)
+		if right != operandTypeRegexp {
+			tc.addErr(cond.RightValue.Pos, fmt.Errorf("%s operand is not valid for %s, expected regexp", right, displayOperator(cond.Op)))
+		}
+	}
+}
+
+func (tc *TypeChecker) checkListCondition(cond ListCondition) {
+	tc.checkMixedValue(cond.LeftValue)
+	for _, mv := range cond.RightValues {
+		tc.checkMixedValue(mv)
+	}
+	if cond.Op == "inignoringcase" || cond.Op == "notinignoringcase" {
+		tc.expect(cond.Op, cond.LeftValue.Pos, tc.typeOfMixedValue(cond.LeftValue), operandTypeString)
+		for _, mv := range cond.RightValues {
+			tc.expect(cond.Op, mv.Pos, tc.typeOfMixedValue(mv), operandTypeString)
+		}
+		return
+	}
+	for _, mv := range cond.RightValues {
+		tc.checkEquality(cond.Op, cond.LeftValue, mv)
+	}
+}
+
+func (tc *TypeChecker) checkEquality(op string, lv, rv MixedValue) {
+	left, right := tc.typeOfMixedValue(lv), tc.typeOfMixedValue(rv)
+	if left == operandTypeRegexp || left == operandTypeList {
+		tc.addErr(lv.Pos, fmt.Errorf("%s operand is not valid for %s", left, displayOperator(op)))
+		return
+	}
+	if right == operandTypeRegexp || right == operandTypeList {
+		tc.addErr(rv.Pos, fmt.Errorf("%s operand is not valid for %s", right, displayOperator(op)))
+		return
+	}
+	if left != operandTypeAny && right != operandTypeAny && left != right {
+		tc.addErr(rv.Pos, fmt.Errorf("%s operand is not valid for %s against %s", right, displayOperator(op), left))
+	}
+}
+
+func (tc *TypeChecker) expect(op string, pos lexer.Position, actual, expected operandType) {
+	if actual != operandTypeAny && actual != expected {
+		tc.addErr(pos, fmt.Errorf("%s operand is not valid for %s, expected %s", actual, displayOperator(op), expected))
+	}
+}
+
+// expectText is the equivalent of expect for a string operand, where the operator is also applied to each item of
+// a list.
+func (tc *TypeChecker) expectText(op string, pos lexer.Position, actual operandType) {
+	if actual != operandTypeList {
+		tc.expect(op, pos, actual, operandTypeString)
+	}
+}
+
+// checkSum verifies the variables within the value of a score change, which must all be numeric.
+func (tc *TypeChecker) checkSum(op string, s Sum) {
+	tc.checkProduct(op, s.Left)
+	for _, so := range s.Right {
+		tc.checkProduct(op, so.Product)
+	}
+}
+
+func (tc *TypeChecker) checkProduct(op string, p Product) {
+	tc.checkNumericValue(op, p.Left)
+	for _, po := range p.Right {
+		tc.checkNumericValue(op, po.Value)
+	}
+}
+
+func (tc *TypeChecker) checkNumericValue(op string, nv NumericValue) {
+	switch {
+	case nv.Var != nil:
+		tc.checkVar(nv.Pos, *nv.Var)
+		tc.expect(op, nv.Pos, tc.typeOfVar(*nv.Var), operandTypeNumber)
+	case nv.Sum != nil:
+		tc.checkSum(op, *nv.Sum)
+	}
+}
+
+func (tc *TypeChecker) checkMixedValue(mv MixedValue) {
+	if mv.Var != nil {
+		tc.checkVar(mv.Pos, *mv.Var)
+	}
+}
+
+// checkVar reports references to undeclared variables, be they declared via DeclareVariables or the input block.
+// All variables are permitted where none have been declared.
+func (tc *TypeChecker) checkVar(pos lexer.Position, name string) {
+	_, input := tc.inputs[name]
+	if (tc.vars != nil && !tc.vars[name]) || (tc.inputs != nil && !input) {
+		tc.addErr(pos, fmt.Errorf("undeclared variable %s", name))
+	}
+}
+
+func (tc *TypeChecker) typeOfMixedValue(mv MixedValue) operandType {
+	switch {
+	case mv.Var != nil:
+		return tc.typeOfVar(*mv.Var)
+	case mv.String != nil:
+		return operandTypeString
+	case mv.Int != nil, mv.Float != nil, mv.Score != nil:
+		return operandTypeNumber
+	case mv.Regexp != nil:
+		return operandTypeRegexp
+	default:
+		return operandTypeAny
+	}
+}
+
+// typeOfVar types a variable by its declaration within the input block. Bools are compared in their textual form,
+// so are typed as strings.
+func (tc *TypeChecker) typeOfVar(name string) operandType {
+	f, ok := tc.inputs[name]
+	if !ok {
+		return operandTypeAny
+	}
+	switch f.Type {
+	case "string", "bool":
+		return operandTypeString
+	case "int", "float":
+		return operandTypeNumber
+	case "list":
+		return operandTypeList
+	}
+	return operandTypeAny
+}
+
+func (tc *TypeChecker) Errors() ErrorList {
+	return tc.errs
+}
+
+func (tc *TypeChecker) addErr(pos lexer.Position, err error) {
+	tc.errs = append(tc.errs, &Error{Pos: pos, Rule: tc.rule, Err: err})
+}
@@ -0,0 +1,269 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/alecthomas/participle/lexer"
+	"github.com/pkg/errors"
+)
+
+// encodingMagic prefixes encoded programs, followed by encodingVersion. The version must be incremented upon
+// any change to the encoding.
+const (
+	encodingMagic   = "BRUL"
+	encodingVersion = 2
+)
+
+type operandTag uint8
+
+const (
+	operandTagNone operandTag = iota
+	operandTagRegexp
+	operandTagVar
+	operandTagInt
+	operandTagFloat
+	operandTagString
+	operandTagScratch
+	operandTagScore
+	operandTagInstructionPosition
+)
+
+// EncodeProgram serialises instructions, along with the schema of the variables they expect, into a versioned binary
+// format, as read by DecodeProgram.
+func EncodeProgram(ins []Instruction, schema Schema) ([]byte, error) {
+	e := &encoder{}
+	e.buf.WriteString(encodingMagic)
+	e.uint(encodingVersion)
+	e.uint(uint64(len(ins)))
+	for _, in := range ins {
+		e.int(int64(in.Operation))
+		e.uint(uint64(in.Ret))
+		e.operand(in.Operand1)
+		e.operand(in.Operand2)
+		e.position(in.Pos)
+		e.string(in.Rule)
+	}
+	e.uint(uint64(len(schema)))
+	for _, f := range schema {
+		e.string(f.Name)
+		e.uint(uint64(f.Kind))
+		e.position(f.Pos)
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// DecodeProgram deserialises instructions and schema encoded by EncodeProgram. The instructions are validated, such
+// that jump targets lie within the instructions and each operation has operands of the types it expects.
+func DecodeProgram(data []byte) ([]Instruction, Schema, error) {
+	if !bytes.HasPrefix(data, []byte(encodingMagic)) {
+		return nil, nil, errors.New("invalid header")
+	}
+	d := &decoder{buf: bytes.NewReader(data[len(encodingMagic):])}
+	if v := d.uint(); d.err == nil && v != encodingVersion {
+		return nil, nil, fmt.Errorf("unsupported version %d", v)
+	}
+	n := d.uint()
+	if d.err == nil && n > uint64(d.buf.Len()) {
+		return nil, nil, fmt.Errorf("instruction count %d exceeds data length", n)
+	}
+	ins := make([]Instruction, 0, n)
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		in := Instruction{
+			Operation: d.operation(),
+			Ret:       ScratchPosition(d.uint()),
+			Operand1:  d.operand(),
+			Operand2:  d.operand(),
+		}
+		in.Pos = d.position()
+		in.Rule = d.string()
+		ins = append(ins, in)
+	}
+	schema := d.schema()
+	if d.err != nil {
+		return nil, nil, d.err
+	}
+	if d.buf.Len() > 0 {
+		return nil, nil, fmt.Errorf("%d bytes of trailing data", d.buf.Len())
+	}
+	if err := ValidateInstructions(ins); err != nil {
+		return nil, nil, err
+	}
+	return ins, schema, nil
+}
+
+type encoder struct {
+	buf bytes.Buffer
+	err error
+}
+
+func (e *encoder) uint(n uint64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf.Write(b[:binary.PutUvarint(b[:], n)])
+}
+
+func (e *encoder) int(n int64) {
+	var b [binary.MaxVarintLen64]byte
+	e.buf.Write(b[:binary.PutVarint(b[:], n)])
+}
+
+func (e *encoder) string(s string) {
+	e.uint(uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+func (e *encoder) position(pos lexer.Position) {
+	e.string(pos.Filename)
+	e.int(int64(pos.Offset))
+	e.int(int64(pos.Line))
+	e.int(int64(pos.Column))
+}
+
+// nolint:gocyclo
+func (e *encoder) operand(op Operand) {
+	switch o := op.(type) {
+	case nil:
+		e.uint(uint64(operandTagNone))
+	case RegexpOperand:
+		e.uint(uint64(operandTagRegexp))
+		e.string(o.Value.String())
+	case VarOperand:
+		e.uint(uint64(operandTagVar))
+		e.string(o.Name)
+	case IntOperand:
+		e.uint(uint64(operandTagInt))
+		e.int(int64(o.Value))
+	case FloatOperand:
+		e.uint(uint64(operandTagFloat))
+		e.uint(math.Float64bits(o.Value))
+	case StringOperand:
+		e.uint(uint64(operandTagString))
+		e.string(o.Value)
+	case ScratchOperand:
+		e.uint(uint64(operandTagScratch))
+		e.uint(uint64(o.Pos))
+	case ScoreOperand:
+		e.uint(uint64(operandTagScore))
+		e.string(o.Name)
+	case InstructionPositionOperand:
+		e.uint(uint64(operandTagInstructionPosition))
+		e.int(int64(o.Pos))
+	default:
+		if e.err == nil {
+			e.err = fmt.Errorf("unable to encode operand of type %T", op)
+		}
+	}
+}
+
+type decoder struct {
+	buf *bytes.Reader
+	err error
+}
+
+func (d *decoder) setErr(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *decoder) uint() uint64 {
+	n, err := binary.ReadUvarint(d.buf)
+	if err != nil {
+		d.setErr(errors.New("truncated data"))
+	}
+	return n
+}
+
+func (d *decoder) int() int64 {
+	n, err := binary.ReadVarint(d.buf)
+	if err != nil {
+		d.setErr(errors.New("truncated data"))
+	}
+	return n
+}
+
+func (d *decoder) string() string {
+	n := d.uint()
+	if n > uint64(d.buf.Len()) {
+		d.setErr(errors.New("truncated data"))
+		return ""
+	}
+	b := make([]byte, n)
+	_, _ = d.buf.Read(b)
+	return string(b)
+}
+
+// operation reads an operation, rejecting values that would be truncated in conversion.
+func (d *decoder) operation() Operation {
+	n := d.int()
+	if n < math.MinInt8 || n > math.MaxInt8 {
+		d.setErr(fmt.Errorf("unknown operation %d", n))
+	}
+	return Operation(n)
+}
+
+func (d *decoder) position() lexer.Position {
+	return lexer.Position{
+		Filename: d.string(),
+		Offset:   int(d.int()),
+		Line:     int(d.int()),
+		Column:   int(d.int()),
+	}
+}
+
+func (d *decoder) schema() Schema {
+	n := d.uint()
+	if d.err == nil && n > uint64(d.buf.Len()) {
+		d.setErr(fmt.Errorf("variable count %d exceeds data length", n))
+	}
+	if d.err != nil || n == 0 {
+		return nil
+	}
+	schema := make(Schema, 0, n)
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		f := SchemaField{Name: d.string(), Kind: ValueKind(d.uint())}
+		if _, ok := valueKindToStringMap[f.Kind]; !ok {
+			d.setErr(fmt.Errorf("unknown kind %d for variable %s", f.Kind, f.Name))
+		}
+		f.Pos = d.position()
+		schema = append(schema, f)
+	}
+	return schema
+}
+
+// nolint:gocyclo
+func (d *decoder) operand() Operand {
+	switch tag := operandTag(d.uint()); tag {
+	case operandTagNone:
+		return nil
+	case operandTagRegexp:
+		rg, err := regexp.Compile(d.string())
+		if err != nil {
+			d.setErr(errors.Wrap(err, "regex compile failed"))
+		}
+		return RegexpOperand{Value: rg}
+	case operandTagVar:
+		return VarOperand{Name: d.string()}
+	case operandTagInt:
+		return IntOperand{Value: int(d.int())}
+	case operandTagFloat:
+		return FloatOperand{Value: math.Float64frombits(d.uint())}
+	case operandTagString:
+		return StringOperand{Value: d.string()}
+	case operandTagScratch:
+		return ScratchOperand{Pos: ScratchPosition(d.uint())}
+	case operandTagScore:
+		return ScoreOperand{Name: d.string()}
+	case operandTagInstructionPosition:
+		return InstructionPositionOperand{Pos: int(d.int())}
+	default:
+		d.setErr(fmt.Errorf("unknown operand tag %d", tag))
+		return nil
+	}
+}
@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/alecthomas/participle/lexer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeProgram_RoundTrip(t *testing.T) {
+	pos := lexer.Position{Filename: "rules", Offset: 12, Line: 2, Column: 3}
+	ins := []Instruction{
+		{Operation: OperationMatches, Ret: 1, Operand1: VarOperand{Name: "a"}, Operand2: RegexpOperand{Value: regexp.MustCompile(`^x+$`)}, Pos: pos, Rule: "r"},
+		{Operation: OperationJumpIfZero, Operand1: ScratchOperand{Pos: 1}, Operand2: InstructionPositionOperand{Pos: 5}, Pos: pos},
+		{Operation: OperationIsEqual, Ret: 1, Operand1: StringOperand{Value: "x"}, Operand2: ScoreOperand{Name: "s"}},
+		{Operation: OperationMul, Ret: 2, Operand1: IntOperand{Value: -3}, Operand2: FloatOperand{Value: 0.35}},
+		{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "s"}, Operand2: ScratchOperand{Pos: 2}},
+		{Operation: OperationNoop},
+	}
+	schema := Schema{
+		{Name: "a", Kind: ValueKindString, Pos: pos},
+		{Name: "b", Kind: ValueKindList},
+	}
+	data, err := EncodeProgram(ins, schema)
+	require.NoError(t, err)
+
+	decoded, decodedSchema, err := DecodeProgram(data)
+	require.NoError(t, err)
+	assert.Equal(t, schema, decodedSchema)
+	require.Len(t, decoded, len(ins))
+	for n := range ins {
+		assert.Equal(t, ins[n].StringSlice(), decoded[n].StringSlice())
+		assert.Equal(t, ins[n].Pos, decoded[n].Pos)
+		assert.Equal(t, ins[n].Rule, decoded[n].Rule)
+	}
+	assert.True(t, decoded[0].Operand2.(RegexpOperand).Value.MatchString("xxx"))
+}
+
+func TestDecodeProgram_Invalid(t *testing.T) {
+	encode := func(ins ...Instruction) []byte {
+		data, err := EncodeProgram(ins, nil)
+		require.NoError(t, err)
+		return data
+	}
+	valid := encode(Instruction{Operation: OperationNoop})
+	// The operation of the sole instruction follows the magic, version and count; 0x82 0x04 is the varint 257.
+	wrapped := append(append(append([]byte{}, valid[:6]...), 0x82, 0x04), valid[7:]...)
+	unknownKind, err := EncodeProgram([]Instruction{{Operation: OperationNoop}}, Schema{{Name: "a", Kind: ValueKind(9)}})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{
+			name:     "bad header",
+			data:     []byte("nope"),
+			expected: "invalid header",
+		},
+		{
+			name:     "unsupported version",
+			data:     []byte("BRUL\x09\x00"),
+			expected: "unsupported version 9",
+		},
+		{
+			name:     "truncated",
+			data:     valid[:len(valid)-1],
+			expected: "truncated data",
+		},
+		{
+			name:     "trailing data",
+			data:     append(append([]byte{}, valid...), 0),
+			expected: "1 bytes of trailing data",
+		},
+		{
+			name:     "jump target out of range",
+			data:     encode(Instruction{Operation: OperationJump, Operand1: InstructionPositionOperand{Pos: 2}}),
+			expected: "instruction 0: jump target 2 out of range",
+		},
+		{
+			name: "backward jump",
+			data: encode(
+				Instruction{Operation: OperationNoop},
+				Instruction{Operation: OperationJump, Operand1: InstructionPositionOperand{Pos: 0}},
+			),
+			expected: "instruction 1: jump target 0 out of range",
+		},
+		{
+			name:     "self jump",
+			data:     encode(Instruction{Operation: OperationJump, Operand1: InstructionPositionOperand{Pos: 0}}, Instruction{Operation: OperationNoop}),
+			expected: "instruction 0: jump target 0 out of range",
+		},
+		{
+			name:     "scratch position beyond int range",
+			data:     encode(Instruction{Operation: OperationNegate, Ret: 1 << 63, Operand1: ScratchOperand{Pos: 1}}),
+			expected: "instruction 0: scratch position $9223372036854775808 out of range",
+		},
+		{
+			name:     "maximum scratch position",
+			data:     encode(Instruction{Operation: OperationNegate, Ret: 1<<64 - 1, Operand1: ScratchOperand{Pos: 1}}),
+			expected: "instruction 0: scratch position $18446744073709551615 out of range",
+		},
+		{
+			name:     "maximum scratch operand",
+			data:     encode(Instruction{Operation: OperationNegate, Ret: 1, Operand1: ScratchOperand{Pos: 1<<64 - 1}}),
+			expected: "instruction 0: scratch position $18446744073709551615 out of range",
+		},
+		{
+			name:     "unfolded string for fold operation",
+			data:     encode(Instruction{Operation: OperationContainsFold, Ret: 1, Operand1: VarOperand{Name: "a"}, Operand2: StringOperand{Value: "Brexit"}}),
+			expected: `instruction 0: string operand "Brexit" is not case folded for CONTAINS_FOLD`,
+		},
+		{
+			name:     "unexpected operand",
+			data:     encode(Instruction{Operation: OperationAddScore, Operand1: VarOperand{Name: "a"}, Operand2: IntOperand{Value: 1}}),
+			expected: "instruction 0: unexpected first operand var(a) for ADD_SCORE",
+		},
+		{
+			name:     "unknown variable kind",
+			data:     unknownKind,
+			expected: "unknown kind 9 for variable a",
+		},
+		{
+			name:     "operation out of range",
+			data:     wrapped,
+			expected: "unknown operation 257",
+		},
+		{
+			name:     "unknown operation",
+			data:     encode(Instruction{Operation: Operation(100)}),
+			expected: "instruction 0: unknown operation 100",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			_, _, err := DecodeProgram(tc.data)
+			assert.EqualError(tt, err, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/participle/lexer"
+)
+
+// Error is an error attributed to a position within the program source, and to the named rule at that position
+// where there is one.
+type Error struct {
+	Pos  lexer.Position
+	Rule string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.Rule != "" {
+		return lexer.FormatError(e.Pos, fmt.Sprintf("rule %q: %s", e.Rule, e.Err))
+	}
+	return lexer.FormatError(e.Pos, e.Err.Error())
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorList is a collection of errors, such as those found in compiling a program.
+type ErrorList []*Error
+
+func (el ErrorList) Error() string {
+	msgs := make([]string, len(el))
+	for i, e := range el {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (el ErrorList) Unwrap() []error {
+	errs := make([]error, len(el))
+	for i, e := range el {
+		errs[i] = e
+	}
+	return errs
+}
+
+// As finds the first error in the list that matches target, as errors.As does. Multiple unwrapped errors are only
+// followed by errors.As from Go 1.20, so this allows the list to be inspected on earlier versions.
+func (el ErrorList) As(target interface{}) bool {
+	for _, e := range el {
+		if errors.As(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Is reports whether any error in the list matches target, as errors.Is does.
+func (el ErrorList) Is(target error) bool {
+	for _, e := range el {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sort orders the errors by their position within the program source.
+func (el ErrorList) Sort() {
+	sort.SliceStable(el, func(i, j int) bool {
+		a, b := el[i].Pos, el[j].Pos
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alecthomas/participle/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorList(t *testing.T) {
+	el := ErrorList{
+		{Pos: lexer.Position{Line: 3, Column: 1}, Err: errors.New("c")},
+		{Pos: lexer.Position{Line: 1, Column: 5}, Err: errors.New("b")},
+		{Pos: lexer.Position{Line: 1, Column: 2}, Err: errors.New("a")},
+	}
+	el.Sort()
+	assert.EqualError(t, el, "1:2: a\n1:5: b\n3:1: c")
+
+	var perr *Error
+	assert.True(t, el.As(&perr))
+	assert.Equal(t, "a", perr.Err.Error())
+	assert.True(t, errors.As(el, &perr))
+
+	sentinel := errors.New("sentinel")
+	el = append(el, &Error{Err: sentinel})
+	assert.True(t, el.Is(sentinel))
+	assert.True(t, errors.Is(el, sentinel))
+	assert.False(t, el.Is(errors.New("other")))
+}
+
+func TestError_Rule(t *testing.T) {
+	err := &Error{Pos: lexer.Position{Line: 2, Column: 3}, Rule: "uk_politics", Err: errors.New("a")}
+	assert.EqualError(t, err, `2:3: rule "uk_politics": a`)
+}
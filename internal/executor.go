@@ -6,79 +6,165 @@ import (
 	"strings"
 )
 
+// Executor runs a set of instructions against variables. An executor is not safe for concurrent use, but may be
+// reused for subsequent runs via Reset.
 type Executor struct {
 	ins     []Instruction
-	vars    map[string]string
-	scratch map[ScratchPosition]bool
-	scores  map[string]int
+	vars    Variables
+	scratch []Number
+	scores  map[string]Number
+	limits  Limits
+	strict  bool
+	tracing bool
+	trace   []TraceEvent
 	err     error
 }
 
-func NewExecutor(ins []Instruction, vars map[string]string) *Executor {
+func NewExecutor(ins []Instruction, vars Variables) *Executor {
+	i := newExecutor(ins, ScratchSize(ins))
+	i.vars = vars
+	return i
+}
+
+func newExecutor(ins []Instruction, scratchSize int) *Executor {
 	return &Executor{
 		ins:     ins,
-		vars:    vars,
-		scratch: map[ScratchPosition]bool{},
-		scores:  map[string]int{},
+		scratch: make([]Number, scratchSize),
+		scores:  map[string]Number{},
+	}
+}
+
+// Reset prepares the executor for a further run against the supplied variables. Storage from the previous run is
+// retained for reuse, with the exception of the trace, which may still be referenced by the caller.
+func (i *Executor) Reset(vars Variables) {
+	i.vars = vars
+	for n := range i.scratch {
+		i.scratch[n] = Number{}
+	}
+	for name := range i.scores {
+		delete(i.scores, name)
 	}
+	i.limits = Limits{}
+	i.strict = false
+	i.tracing = false
+	i.trace = nil
+	i.err = nil
+}
+
+// release drops references to the variables, limits and trace of the previous run, such that an idle executor does
+// not keep them alive.
+func (i *Executor) release() {
+	i.vars = nil
+	i.limits = Limits{}
+	i.trace = nil
 }
 
 // nolint:gocyclo
 func (i *Executor) Execute() {
-	pos := 0
+	pos, count := 0, 0
 Loop:
 	for pos < len(i.ins) {
 		ins := i.ins[pos]
+		next := pos + 1
+		count++
+		if err := i.limits.check(count); err != nil {
+			i.err = &Error{Pos: ins.Pos, Rule: ins.Rule, Err: err}
+			break
+		}
+		var before Number
+		if i.tracing && ins.Operation.IsScoreChange() {
+			before = i.scores[i.scoreNameFromOperand(ins.Operand1)]
+		}
 		switch ins.Operation {
 		case OperationIsEqual:
-			i.scratch[ins.Ret] = i.operandsEqual(ins.Operand1, ins.Operand2)
+			i.setScratch(ins.Ret, i.operandsEqual(ins.Operand1, ins.Operand2))
 		case OperationIsNotEqual:
-			i.scratch[ins.Ret] = !i.operandsEqual(ins.Operand1, ins.Operand2)
+			i.setScratch(ins.Ret, !i.operandsEqual(ins.Operand1, ins.Operand2))
 		case OperationIsGreaterThan:
-			i.scratch[ins.Ret] = i.intFromOperand(ins.Operand1) > i.intFromOperand(ins.Operand2)
+			i.setScratch(ins.Ret, i.compareOperands(ins.Operand1, ins.Operand2) > 0)
 		case OperationIsGreaterThanOrEqual:
-			i.scratch[ins.Ret] = i.intFromOperand(ins.Operand1) >= i.intFromOperand(ins.Operand2)
+			i.setScratch(ins.Ret, i.compareOperands(ins.Operand1, ins.Operand2) >= 0)
 		case OperationIsLessThan:
-			i.scratch[ins.Ret] = i.intFromOperand(ins.Operand1) < i.intFromOperand(ins.Operand2)
+			i.setScratch(ins.Ret, i.compareOperands(ins.Operand1, ins.Operand2) < 0)
 		case OperationIsLessThanOrEqual:
-			i.scratch[ins.Ret] = i.intFromOperand(ins.Operand1) <= i.intFromOperand(ins.Operand2)
+			i.setScratch(ins.Ret, i.compareOperands(ins.Operand1, ins.Operand2) <= 0)
 		case OperationContains:
-			i.scratch[ins.Ret] = strings.Contains(i.stringFromOperand(ins.Operand1), i.stringFromOperand(ins.Operand2))
+			i.setScratch(ins.Ret, i.operandContains(ins.Operand1, ins.Operand2))
 		case OperationDoesNotContain:
-			i.scratch[ins.Ret] = !strings.Contains(i.stringFromOperand(ins.Operand1), i.stringFromOperand(ins.Operand2))
+			i.setScratch(ins.Ret, !i.operandContains(ins.Operand1, ins.Operand2))
 		case OperationMatches:
-			i.scratch[ins.Ret] = i.regexpFromOperand(ins.Operand2).MatchString(i.stringFromOperand(ins.Operand1))
+			i.setScratch(ins.Ret, i.operandMatches(ins.Operand1, ins.Operand2))
 		case OperationDoesNotMatch:
-			i.scratch[ins.Ret] = !i.regexpFromOperand(ins.Operand2).MatchString(i.stringFromOperand(ins.Operand1))
+			i.setScratch(ins.Ret, !i.operandMatches(ins.Operand1, ins.Operand2))
+		case OperationIsEqualFold:
+			i.setScratch(ins.Ret, i.foldedStringFromOperand(ins.Operand1) == i.foldedStringFromOperand(ins.Operand2))
+		case OperationContainsFold:
+			i.setScratch(ins.Ret, i.operandContainsFold(ins.Operand1, ins.Operand2))
+		case OperationDoesNotContainFold:
+			i.setScratch(ins.Ret, !i.operandContainsFold(ins.Operand1, ins.Operand2))
+		case OperationStartsWith:
+			i.setScratch(ins.Ret, i.operandAffixed(ins.Operand1, ins.Operand2, strings.HasPrefix))
+		case OperationDoesNotStartWith:
+			i.setScratch(ins.Ret, !i.operandAffixed(ins.Operand1, ins.Operand2, strings.HasPrefix))
+		case OperationEndsWith:
+			i.setScratch(ins.Ret, i.operandAffixed(ins.Operand1, ins.Operand2, strings.HasSuffix))
+		case OperationDoesNotEndWith:
+			i.setScratch(ins.Ret, !i.operandAffixed(ins.Operand1, ins.Operand2, strings.HasSuffix))
+		case OperationIsSet:
+			i.setScratch(ins.Ret, i.varIsSet(ins.Operand1))
+		case OperationIsNotSet:
+			i.setScratch(ins.Ret, !i.varIsSet(ins.Operand1))
+		case OperationIsEmpty:
+			i.setScratch(ins.Ret, i.varIsEmpty(ins.Operand1))
 		case OperationJumpIfZero:
 			sv := i.scratchVarFromOperand(ins.Operand1)
-			i.scratch[ins.Ret] = !sv
+			i.setScratch(ins.Ret, !sv)
 			if !sv {
-				pos = i.instructionPositionFromOperand(ins.Operand2)
-				continue
+				next = i.instructionPositionFromOperand(ins.Operand2)
 			}
 		case OperationJumpIfNotZero:
 			sv := i.scratchVarFromOperand(ins.Operand1)
-			i.scratch[ins.Ret] = sv
+			i.setScratch(ins.Ret, sv)
 			if sv {
-				pos = i.instructionPositionFromOperand(ins.Operand2)
-				continue
+				next = i.instructionPositionFromOperand(ins.Operand2)
 			}
+		case OperationJump:
+			next = i.instructionPositionFromOperand(ins.Operand1)
 		case OperationAddScore:
 			name := i.scoreNameFromOperand(ins.Operand1)
-			val := i.intFromOperand(ins.Operand2)
-			i.scores[name] += val
+			val := i.numberFromOperand(ins.Operand2)
+			i.scores[name] = i.score(name).Add(val)
 		case OperationSubScore:
 			name := i.scoreNameFromOperand(ins.Operand1)
-			val := i.intFromOperand(ins.Operand2)
-			i.scores[name] -= val
+			val := i.numberFromOperand(ins.Operand2)
+			i.scores[name] = i.score(name).Sub(val)
 		case OperationSetScore:
 			name := i.scoreNameFromOperand(ins.Operand1)
-			val := i.intFromOperand(ins.Operand2)
+			val := i.numberFromOperand(ins.Operand2)
 			i.scores[name] = val
+		case OperationMulScore:
+			name := i.scoreNameFromOperand(ins.Operand1)
+			val := i.numberFromOperand(ins.Operand2)
+			i.scores[name] = i.score(name).Mul(val)
+		case OperationDivScore:
+			name := i.scoreNameFromOperand(ins.Operand1)
+			val := i.numberFromOperand(ins.Operand2)
+			i.scores[name] = i.checkNumber(i.score(name).Div(val))
+		case OperationModScore:
+			name := i.scoreNameFromOperand(ins.Operand1)
+			val := i.numberFromOperand(ins.Operand2)
+			i.scores[name] = i.checkNumber(i.score(name).Mod(val))
 		case OperationNegate:
 			val := i.scratchVarFromOperand(ins.Operand1)
-			i.scratch[ins.Ret] = !val
+			i.setScratch(ins.Ret, !val)
+		case OperationAdd:
+			i.scratch[ins.Ret] = i.numberFromOperand(ins.Operand1).Add(i.numberFromOperand(ins.Operand2))
+		case OperationSub:
+			i.scratch[ins.Ret] = i.numberFromOperand(ins.Operand1).Sub(i.numberFromOperand(ins.Operand2))
+		case OperationMul:
+			i.scratch[ins.Ret] = i.numberFromOperand(ins.Operand1).Mul(i.numberFromOperand(ins.Operand2))
+		case OperationDiv:
+			i.scratch[ins.Ret] = i.checkNumber(i.numberFromOperand(ins.Operand1).Div(i.numberFromOperand(ins.Operand2)))
 		case OperationExit:
 			break Loop
 		case OperationNoop:
@@ -87,16 +173,97 @@ Loop:
 			i.setErr(fmt.Errorf("unexpected operation %v", ins.Operation))
 		}
 		if i.err != nil {
+			i.err = &Error{Pos: ins.Pos, Rule: ins.Rule, Err: i.err}
 			break Loop
 		}
-		pos++
+		if i.tracing {
+			i.record(ins, before)
+		}
+		pos = next
 	}
 }
 
-func (i *Executor) Scores() map[string]int {
+// SetLimits bounds the cost of subsequent execution.
+func (i *Executor) SetLimits(l Limits) {
+	i.limits = l
+}
+
+// EnableStrict instructs the executor to fail upon reading a variable absent from the input, or a score yet to be
+// assigned, rather than treating them as empty and zero respectively.
+func (i *Executor) EnableStrict() {
+	i.strict = true
+}
+
+// EnableTrace instructs the executor to record the conditions evaluated and score changes applied.
+func (i *Executor) EnableTrace() {
+	i.tracing = true
+}
+
+func (i *Executor) Trace() []TraceEvent {
+	return i.trace
+}
+
+func (i *Executor) record(ins Instruction, before Number) {
+	switch {
+	case ins.Operation.IsCondition():
+		i.trace = append(i.trace, TraceEvent{
+			Pos:       ins.Pos,
+			Rule:      ins.Rule,
+			Operation: ins.Operation,
+			Operand1:  ins.Operand1,
+			Operand2:  ins.Operand2,
+			Result:    !i.scratch[ins.Ret].IsZero(),
+		})
+	case ins.Operation.IsScoreChange():
+		name := i.scoreNameFromOperand(ins.Operand1)
+		i.trace = append(i.trace, TraceEvent{
+			Pos:       ins.Pos,
+			Rule:      ins.Rule,
+			Operation: ins.Operation,
+			Operand1:  ins.Operand1,
+			Operand2:  ins.Operand2,
+			Score:     name,
+			Before:    before,
+			After:     i.scores[name],
+		})
+	}
+}
+
+func (i *Executor) setScratch(pos ScratchPosition, b bool) {
+	if b {
+		i.scratch[pos] = IntNumber(1)
+	} else {
+		i.scratch[pos] = IntNumber(0)
+	}
+}
+
+func (i *Executor) checkNumber(n Number, err error) Number {
+	if err != nil {
+		i.setErr(err)
+	}
+	return n
+}
+
+func (i *Executor) Scores() map[string]Number {
 	return i.scores
 }
 
+func (i *Executor) IntScores() map[string]int {
+	scores := make(map[string]int, len(i.scores))
+	for name, n := range i.scores {
+		scores[name] = n.Int()
+	}
+	return scores
+}
+
+func (i *Executor) FloatScores() map[string]float64 {
+	scores := make(map[string]float64, len(i.scores))
+	for name, n := range i.scores {
+		scores[name] = n.Float()
+	}
+	return scores
+}
+
 func (i *Executor) Err() error {
 	return i.err
 }
@@ -109,28 +276,150 @@ func (i *Executor) setErr(err error) {
 
 func (i *Executor) operandsEqual(op1, op2 Operand) bool {
 	switch o := op1.(type) {
-	case IntOperand:
-		return o.Value == i.intFromOperand(op2)
+	case IntOperand, FloatOperand, ScoreOperand:
+		return i.compareOperands(op1, op2) == 0
 	case StringOperand:
 		return o.Value == i.stringFromOperand(op2)
-	case ScoreOperand:
-		return i.scores[o.Name] == i.intFromOperand(op2)
 	case VarOperand:
-		return i.vars[o.Name] == i.stringFromOperand(op2)
+		switch op2.(type) {
+		case IntOperand, FloatOperand, ScoreOperand:
+			return i.compareOperands(op1, op2) == 0
+		}
+		return i.stringFromOperand(op1) == i.stringFromOperand(op2)
 	default:
 		i.setErr(fmt.Errorf("unexpected operand of type %T for equality check", op1))
 	}
 	return false
 }
 
-func (i *Executor) intFromOperand(op Operand) (v int) {
+func (i *Executor) compareOperands(op1, op2 Operand) int {
+	return i.numberFromOperand(op1).Compare(i.numberFromOperand(op2))
+}
+
+func (i *Executor) operandContains(op1, op2 Operand) bool {
+	if l, ok := i.listFromOperand(op1); ok {
+		s := i.stringFromOperand(op2)
+		for _, item := range l {
+			if item == s {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(i.stringFromOperand(op1), i.stringFromOperand(op2))
+}
+
+// operandContainsFold is the case-insensitive equivalent of operandContains.
+func (i *Executor) operandContainsFold(op1, op2 Operand) bool {
+	if l, ok := i.listFromOperand(op1); ok {
+		s := i.foldedStringFromOperand(op2)
+		for _, item := range l {
+			if foldCase(item) == s {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(i.foldedStringFromOperand(op1), i.foldedStringFromOperand(op2))
+}
+
+// operandAffixed tests the first operand for the second as a prefix or suffix, according to the supplied function.
+// Where the first operand is a list, any item carrying the affix suffices.
+func (i *Executor) operandAffixed(op1, op2 Operand, affixed func(s, affix string) bool) bool {
+	affix := i.stringFromOperand(op2)
+	if l, ok := i.listFromOperand(op1); ok {
+		for _, item := range l {
+			if affixed(item, affix) {
+				return true
+			}
+		}
+		return false
+	}
+	return affixed(i.stringFromOperand(op1), affix)
+}
+
+func (i *Executor) operandMatches(op1, op2 Operand) bool {
+	rg := i.regexpFromOperand(op2)
+	if rg == nil {
+		return false
+	}
+	if l, ok := i.listFromOperand(op1); ok {
+		for _, item := range l {
+			if rg.MatchString(item) {
+				return true
+			}
+		}
+		return false
+	}
+	return rg.MatchString(i.stringFromOperand(op1))
+}
+
+func (i *Executor) varIsSet(op Operand) bool {
+	o, ok := op.(VarOperand)
+	if !ok {
+		i.setErr(fmt.Errorf("unexpected operand of type %T for presence check", op))
+		return false
+	}
+	_, ok = i.vars.Lookup(o.Name)
+	return ok
+}
+
+// varIsEmpty reports whether the variable is set, and holds either an empty string or an empty list.
+func (i *Executor) varIsEmpty(op Operand) bool {
+	o, ok := op.(VarOperand)
+	if !ok {
+		i.setErr(fmt.Errorf("unexpected operand of type %T for presence check", op))
+		return false
+	}
+	val, ok := i.vars.Lookup(o.Name)
+	if !ok {
+		return false
+	}
+	switch val.Kind() {
+	case ValueKindString:
+		s, _ := val.AsString()
+		return s == ""
+	case ValueKindList:
+		return len(val.List()) == 0
+	}
+	return false
+}
+
+func (i *Executor) lookupVar(name string) Value {
+	v, ok := i.vars.Lookup(name)
+	if !ok && i.strict {
+		i.setErr(fmt.Errorf("undefined variable %s", name))
+	}
+	return v
+}
+
+func (i *Executor) score(name string) Number {
+	n, ok := i.scores[name]
+	if !ok && i.strict {
+		i.setErr(fmt.Errorf("undefined score %s", name))
+	}
+	return n
+}
+
+func (i *Executor) numberFromOperand(op Operand) (n Number) {
 	switch o := op.(type) {
 	case IntOperand:
-		v = o.Value
+		n = IntNumber(o.Value)
+	case FloatOperand:
+		n = FloatNumber(o.Value)
 	case ScoreOperand:
-		v = i.scores[o.Name]
+		n = i.score(o.Name)
+	case ScratchOperand:
+		n = i.scratch[o.Pos]
+	case VarOperand:
+		val := i.lookupVar(o.Name)
+		num, ok := val.AsNumber()
+		if !ok {
+			i.setErr(fmt.Errorf("could not coerce %s value %q of %s into number", val.Kind(), val, o))
+		}
+		n = num
 	default:
-		i.setErr(fmt.Errorf("could not coerce operand of type %T into int", op))
+		i.setErr(fmt.Errorf("could not coerce operand of type %T into number", op))
 	}
 	return
 }
@@ -140,13 +429,37 @@ func (i *Executor) stringFromOperand(op Operand) (s string) {
 	case StringOperand:
 		s = o.Value
 	case VarOperand:
-		s = i.vars[o.Name]
+		val := i.lookupVar(o.Name)
+		str, ok := val.AsString()
+		if !ok {
+			i.setErr(fmt.Errorf("could not coerce %s value of %s into string", val.Kind(), o))
+		}
+		s = str
 	default:
 		i.setErr(fmt.Errorf("could not coerce operand of type %T into string", op))
 	}
 	return
 }
 
+// foldedStringFromOperand resolves the operand into a case folded string. String literals are folded at compile
+// time, so only variables are folded here.
+func (i *Executor) foldedStringFromOperand(op Operand) string {
+	s := i.stringFromOperand(op)
+	if _, ok := op.(VarOperand); ok {
+		return foldCase(s)
+	}
+	return s
+}
+
+func (i *Executor) listFromOperand(op Operand) ([]string, bool) {
+	if o, ok := op.(VarOperand); ok {
+		if val := i.lookupVar(o.Name); val.Kind() == ValueKindList {
+			return val.List(), true
+		}
+	}
+	return nil, false
+}
+
 func (i *Executor) regexpFromOperand(op Operand) (r *regexp.Regexp) {
 	switch o := op.(type) {
 	case RegexpOperand:
@@ -170,7 +483,7 @@ func (i *Executor) instructionPositionFromOperand(op Operand) (p int) {
 func (i *Executor) scratchVarFromOperand(op Operand) (b bool) {
 	switch o := op.(type) {
 	case ScratchOperand:
-		b = i.scratch[o.Pos]
+		b = !i.scratch[o.Pos].IsZero()
 	default:
 		i.setErr(fmt.Errorf("could not coerce operand of type %T into scratch variable", op))
 	}
@@ -1,9 +1,13 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"regexp"
 	"testing"
+	"time"
 
+	"github.com/alecthomas/participle/lexer"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -11,6 +15,7 @@ func TestExecutor_Execute(t *testing.T) {
 	testCases := []struct {
 		name     string
 		ins      []Instruction
+		vars     map[string]string
 		expected map[string]int
 	}{
 		{
@@ -154,6 +159,28 @@ func TestExecutor_Execute(t *testing.T) {
 			},
 			expected: map[string]int{},
 		},
+		{
+			name: "is greater than check with var, pass",
+			ins: []Instruction{
+				{Operation: OperationIsGreaterThan, Ret: 1, Operand1: VarOperand{Name: "a"}, Operand2: IntOperand{Value: 1}},
+				{Operation: OperationJumpIfZero, Operand1: ScratchOperand{Pos: 1}, Operand2: InstructionPositionOperand{Pos: 3}},
+				{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "x"}, Operand2: IntOperand{Value: 1}},
+				{Operation: OperationNoop},
+			},
+			vars:     map[string]string{"a": "2"},
+			expected: map[string]int{"x": 1},
+		},
+		{
+			name: "is equal check with var and int, pass",
+			ins: []Instruction{
+				{Operation: OperationIsEqual, Ret: 1, Operand1: VarOperand{Name: "a"}, Operand2: IntOperand{Value: 10}},
+				{Operation: OperationJumpIfZero, Operand1: ScratchOperand{Pos: 1}, Operand2: InstructionPositionOperand{Pos: 3}},
+				{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "x"}, Operand2: IntOperand{Value: 1}},
+				{Operation: OperationNoop},
+			},
+			vars:     map[string]string{"a": "010"},
+			expected: map[string]int{"x": 1},
+		},
 		{
 			name: "contains check, pass",
 			ins: []Instruction{
@@ -223,6 +250,29 @@ func TestExecutor_Execute(t *testing.T) {
 			},
 			expected: map[string]int{"x": 2, "y": 1},
 		},
+		{
+			name: "multiplicative score adjustments",
+			ins: []Instruction{
+				{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "x"}, Operand2: IntOperand{Value: 7}},
+				{Operation: OperationMulScore, Operand1: ScoreOperand{Name: "x"}, Operand2: IntOperand{Value: 3}},
+				{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "y"}, Operand2: ScoreOperand{Name: "x"}},
+				{Operation: OperationDivScore, Operand1: ScoreOperand{Name: "y"}, Operand2: IntOperand{Value: 2}},
+				{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "z"}, Operand2: ScoreOperand{Name: "x"}},
+				{Operation: OperationModScore, Operand1: ScoreOperand{Name: "z"}, Operand2: IntOperand{Value: 4}},
+			},
+			expected: map[string]int{"x": 21, "y": 10, "z": 1},
+		},
+		{
+			name: "arithmetic",
+			ins: []Instruction{
+				{Operation: OperationAdd, Ret: 1, Operand1: IntOperand{Value: 1}, Operand2: IntOperand{Value: 2}},
+				{Operation: OperationMul, Ret: 1, Operand1: ScratchOperand{Pos: 1}, Operand2: IntOperand{Value: 5}},
+				{Operation: OperationSub, Ret: 1, Operand1: ScratchOperand{Pos: 1}, Operand2: IntOperand{Value: 3}},
+				{Operation: OperationDiv, Ret: 1, Operand1: ScratchOperand{Pos: 1}, Operand2: IntOperand{Value: 4}},
+				{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "x"}, Operand2: ScratchOperand{Pos: 1}},
+			},
+			expected: map[string]int{"x": 3},
+		},
 		{
 			name: "scratch negate",
 			ins: []Instruction{
@@ -234,6 +284,15 @@ func TestExecutor_Execute(t *testing.T) {
 			},
 			expected: map[string]int{"x": 1},
 		},
+		{
+			name: "jump",
+			ins: []Instruction{
+				{Operation: OperationJump, Operand1: InstructionPositionOperand{Pos: 2}},
+				{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "x"}, Operand2: IntOperand{Value: 1}},
+				{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "y"}, Operand2: IntOperand{Value: 1}},
+			},
+			expected: map[string]int{"y": 1},
+		},
 		{
 			name: "exit",
 			ins: []Instruction{
@@ -246,10 +305,208 @@ func TestExecutor_Execute(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(tt *testing.T) {
-			ex := NewExecutor(tc.ins, map[string]string{})
+			ex := NewExecutor(tc.ins, StringVariables(tc.vars))
 			ex.Execute()
 			assert.NoError(tt, ex.Err())
-			assert.Equal(tt, tc.expected, ex.Scores())
+			assert.Equal(tt, tc.expected, ex.IntScores())
+		})
+	}
+}
+
+func TestExecutor_FloatScores(t *testing.T) {
+	ins := []Instruction{
+		{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "x"}, Operand2: IntOperand{Value: 1}},
+		{Operation: OperationAddScore, Operand1: ScoreOperand{Name: "x"}, Operand2: FloatOperand{Value: 0.5}},
+		{Operation: OperationMul, Ret: 1, Operand1: ScoreOperand{Name: "x"}, Operand2: FloatOperand{Value: 2}},
+		{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "y"}, Operand2: ScratchOperand{Pos: 1}},
+		{Operation: OperationDivScore, Operand1: ScoreOperand{Name: "y"}, Operand2: IntOperand{Value: 4}},
+	}
+	ex := NewExecutor(ins, StringVariables{})
+	ex.Execute()
+	assert.NoError(t, ex.Err())
+	assert.Equal(t, map[string]float64{"x": 1.5, "y": 0.75}, ex.FloatScores())
+	assert.Equal(t, map[string]int{"x": 1, "y": 0}, ex.IntScores())
+}
+
+func TestExecutor_Execute_Errors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ins      []Instruction
+		vars     map[string]string
+		expected string
+	}{
+		{
+			name: "non-numeric var in int comparison",
+			ins: []Instruction{
+				{Operation: OperationIsGreaterThan, Ret: 1, Operand1: VarOperand{Name: "a"}, Operand2: IntOperand{Value: 1}},
+			},
+			vars:     map[string]string{"a": "abc"},
+			expected: `could not coerce string value "abc" of var(a) into number`,
+		},
+		{
+			name: "missing var in int comparison",
+			ins: []Instruction{
+				{Operation: OperationIsLessThan, Ret: 1, Operand1: VarOperand{Name: "a"}, Operand2: IntOperand{Value: 1}},
+			},
+			expected: `could not coerce string value "" of var(a) into number`,
+		},
+		{
+			name: "division by zero",
+			ins: []Instruction{
+				{Operation: OperationDiv, Ret: 1, Operand1: IntOperand{Value: 1}, Operand2: IntOperand{Value: 0}},
+			},
+			expected: "division by zero",
+		},
+		{
+			name: "error with position",
+			ins: []Instruction{
+				{Operation: OperationNoop},
+				{Operation: OperationDiv, Ret: 1, Operand1: IntOperand{Value: 1}, Operand2: IntOperand{Value: 0}, Pos: lexer.Position{Line: 3, Column: 4}},
+			},
+			expected: "3:4: division by zero",
+		},
+		{
+			name: "score division by zero",
+			ins: []Instruction{
+				{Operation: OperationDivScore, Operand1: ScoreOperand{Name: "x"}, Operand2: IntOperand{Value: 0}},
+			},
+			expected: "division by zero",
+		},
+		{
+			name: "score modulo by zero",
+			ins: []Instruction{
+				{Operation: OperationModScore, Operand1: ScoreOperand{Name: "x"}, Operand2: ScoreOperand{Name: "y"}},
+			},
+			expected: "modulo by zero",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			ex := NewExecutor(tc.ins, StringVariables(tc.vars))
+			ex.Execute()
+			assert.EqualError(tt, ex.Err(), tc.expected)
+		})
+	}
+}
+
+func TestExecutor_Trace(t *testing.T) {
+	pos := lexer.Position{Line: 2, Column: 3}
+	ins := []Instruction{
+		{Operation: OperationIsEqual, Ret: 1, Operand1: VarOperand{Name: "a"}, Operand2: StringOperand{Value: "x"}, Pos: pos},
+		{Operation: OperationJumpIfZero, Operand1: ScratchOperand{Pos: 1}, Operand2: InstructionPositionOperand{Pos: 3}},
+		{Operation: OperationAddScore, Operand1: ScoreOperand{Name: "s"}, Operand2: IntOperand{Value: 5}, Pos: pos},
+	}
+	ex := NewExecutor(ins, StringVariables{"a": "x"})
+	ex.EnableTrace()
+	ex.Execute()
+	assert.NoError(t, ex.Err())
+	assert.Equal(t, []TraceEvent{
+		{Pos: pos, Operation: OperationIsEqual, Operand1: ins[0].Operand1, Operand2: ins[0].Operand2, Result: true},
+		{Pos: pos, Operation: OperationAddScore, Operand1: ins[2].Operand1, Operand2: ins[2].Operand2, Score: "s", Before: IntNumber(0), After: IntNumber(5)},
+	}, ex.Trace())
+}
+
+func TestExecutor_Execute_Limits(t *testing.T) {
+	ins := []Instruction{
+		{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "x"}, Operand2: IntOperand{Value: 1}, Pos: lexer.Position{Line: 1, Column: 1}},
+		{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "y"}, Operand2: IntOperand{Value: 2}, Pos: lexer.Position{Line: 2, Column: 1}},
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name     string
+		limits   Limits
+		expected error
+	}{
+		{
+			name:   "within limits",
+			limits: Limits{Context: context.Background(), MaxInstructions: 2, Deadline: time.Now().Add(time.Hour)},
+		},
+		{
+			name:     "instruction limit",
+			limits:   Limits{MaxInstructions: 1},
+			expected: ErrInstructionLimitExceeded,
+		},
+		{
+			name:     "cancelled context",
+			limits:   Limits{Context: cancelled},
+			expected: context.Canceled,
+		},
+		{
+			name:     "deadline passed",
+			limits:   Limits{Deadline: time.Now().Add(-time.Second)},
+			expected: ErrTimeLimitExceeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			ex := NewExecutor(ins, StringVariables{})
+			ex.SetLimits(tc.limits)
+			ex.Execute()
+			if tc.expected == nil {
+				assert.NoError(tt, ex.Err())
+				return
+			}
+			assert.True(tt, errors.Is(ex.Err(), tc.expected))
+		})
+	}
+}
+
+func TestExecutor_Execute_Strict(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ins      []Instruction
+		vars     map[string]string
+		expected string
+	}{
+		{
+			name: "defined var and score",
+			ins: []Instruction{
+				{Operation: OperationSetScore, Operand1: ScoreOperand{Name: "x"}, Operand2: IntOperand{Value: 1}},
+				{Operation: OperationIsEqual, Ret: 1, Operand1: VarOperand{Name: "a"}, Operand2: StringOperand{Value: "x"}},
+				{Operation: OperationAddScore, Operand1: ScoreOperand{Name: "x"}, Operand2: ScoreOperand{Name: "x"}},
+			},
+			vars: map[string]string{"a": "x"},
+		},
+		{
+			name: "presence check of undefined var",
+			ins: []Instruction{
+				{Operation: OperationIsSet, Ret: 1, Operand1: VarOperand{Name: "a"}},
+			},
+		},
+		{
+			name: "undefined var",
+			ins: []Instruction{
+				{Operation: OperationContains, Ret: 1, Operand1: VarOperand{Name: "a"}, Operand2: StringOperand{Value: "x"}},
+			},
+			expected: "undefined variable a",
+		},
+		{
+			name: "undefined score operand",
+			ins: []Instruction{
+				{Operation: OperationIsGreaterThan, Ret: 1, Operand1: ScoreOperand{Name: "x"}, Operand2: IntOperand{Value: 1}},
+			},
+			expected: "undefined score x",
+		},
+		{
+			name: "change to undefined score",
+			ins: []Instruction{
+				{Operation: OperationAddScore, Operand1: ScoreOperand{Name: "x"}, Operand2: IntOperand{Value: 1}},
+			},
+			expected: "undefined score x",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			ex := NewExecutor(tc.ins, StringVariables(tc.vars))
+			ex.EnableStrict()
+			ex.Execute()
+			if tc.expected == "" {
+				assert.NoError(tt, ex.Err())
+				return
+			}
+			assert.EqualError(tt, ex.Err(), tc.expected)
 		})
 	}
 }
@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"strings"
+	"unicode"
+)
+
+// foldCase maps a string to a canonical form under Unicode simple case folding, such that strings which are equal
+// under strings.EqualFold fold to the same string.
+func foldCase(s string) string {
+	return strings.Map(foldRune, s)
+}
+
+// foldRune maps a rune to the lower case of the smallest rune within its case folding orbit, which is shared by
+// every rune in the orbit.
+func foldRune(r rune) rune {
+	min := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return unicode.ToLower(min)
+}
+
+// foldOperand case folds string literal operands, leaving others untouched.
+func foldOperand(op Operand) Operand {
+	if so, ok := op.(StringOperand); ok {
+		return StringOperand{Value: foldCase(so.Value)}
+	}
+	return op
+}
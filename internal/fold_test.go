@@ -0,0 +1,27 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFoldCase(t *testing.T) {
+	testCases := []struct {
+		a, b string
+	}{
+		{"Brexit", "BREXIT"},
+		{"élections", "ÉLECTIONS"},
+		{"σ", "Σ"},
+		{"ς", "Σ"},
+		{"k", "\u212a"},
+		{"s", "ſ"},
+	}
+	for _, tc := range testCases {
+		assert.True(t, strings.EqualFold(tc.a, tc.b))
+		assert.Equal(t, foldCase(tc.a), foldCase(tc.b), "%s and %s", tc.a, tc.b)
+	}
+	assert.Equal(t, "brexit", foldCase("Brexit"))
+	assert.NotEqual(t, foldCase("a"), foldCase("b"))
+}
@@ -0,0 +1,347 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/participle/lexer"
+)
+
+const (
+	formatIndent = "  "
+	// formatListWidth is the width beyond which list conditions are wrapped with an item per line.
+	formatListWidth = 80
+)
+
+// Format re-emits the source of a program in canonical form. Comments are retained, being placed before the line
+// that followed them in the source, or at the end of the line that preceded them where they trailed code.
+func Format(src []byte) ([]byte, error) {
+	root, err := Parse(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := formatTokens(src)
+	if err != nil {
+		return nil, err
+	}
+	f := &formatter{tokens: tokens}
+	for _, t := range tokens {
+		if t.Type == lexr.Symbols()["Comment"] {
+			f.comments = append(f.comments, t)
+		}
+	}
+	if root.Input != nil {
+		f.input(*root.Input)
+	}
+	f.statements(root.Statements, 0)
+	f.flush(len(src)+1, 0, true)
+	if len(f.lines) == 0 {
+		return []byte{}, nil
+	}
+	return []byte(strings.Join(f.lines, "\n") + "\n"), nil
+}
+
+// formatTokens lexes the source, retaining the comments which the parser elides.
+func formatTokens(src []byte) ([]lexer.Token, error) {
+	lex, err := lexr.Lex(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	var tokens []lexer.Token
+	for {
+		t, err := lex.Next()
+		if err != nil {
+			return nil, err
+		}
+		if t.EOF() {
+			return tokens, nil
+		}
+		if t.Type != lexr.Symbols()["Whitespace"] {
+			tokens = append(tokens, t)
+		}
+	}
+}
+
+type formatter struct {
+	tokens   []lexer.Token
+	comments []lexer.Token
+	lines    []string
+	// cursor holds the source offset of the most recently emitted line, from which keywords are searched.
+	cursor int
+	// opened is set when the most recently emitted line opened a block or formed part of an expression, such that
+	// blank lines from the source are not carried over.
+	opened bool
+	// trailed holds the number of lines emitted when a comment was last emitted, such that further trailing
+	// comments, which trailed separate source lines of a node that has been re-flowed, are not merged into its line.
+	trailed int
+}
+
+// input emits the input block on a single line, or with a field per line where that would be too wide.
+func (f *formatter) input(input Input) {
+	fields := make([]string, len(input.Fields))
+	for n, field := range input.Fields {
+		fields[n] = field.Name + ": " + field.Type
+	}
+	text := "input {}"
+	if len(fields) > 0 {
+		text = "input { " + strings.Join(fields, ", ") + " }"
+	}
+	if len(text) > formatListWidth {
+		text = "input {\n" + formatIndent + strings.Join(fields, ",\n"+formatIndent) + "\n}"
+	}
+	f.line(input.Pos.Offset, 0, text)
+	f.opened = false
+}
+
+func (f *formatter) statements(statements []Statement, depth int) {
+	for _, s := range statements {
+		f.flush(s.Pos.Offset, depth, true)
+		if f.blankable() && f.gapBefore(s.Pos.Offset) {
+			f.lines = append(f.lines, "")
+		}
+		switch {
+		case s.Rule != nil:
+			f.rule(*s.Rule, depth)
+		case s.ScoreChange != nil:
+			f.line(s.Pos.Offset, depth, f.scoreChange(*s.ScoreChange))
+			f.opened = false
+		case s.Exit:
+			f.line(s.Pos.Offset, depth, "exit")
+			f.opened = false
+		}
+	}
+}
+
+func (f *formatter) rule(rule Rule, depth int) {
+	if rule.Name != nil {
+		f.line(rule.Pos.Offset, depth, "rule "+strconv.Quote(*rule.Name))
+		f.keyword("when", depth, depth)
+	} else {
+		f.line(rule.Pos.Offset, depth, "when")
+	}
+	f.expression(rule.Expression, depth+1)
+	f.keyword("then", depth+1, depth)
+	f.statements(rule.Consequences.Consequences, depth+1)
+	for _, ew := range rule.ElseWhens {
+		f.flush(ew.Pos.Offset, depth+1, false)
+		f.line(ew.Pos.Offset, depth, "else when")
+		f.expression(ew.Expression, depth+1)
+		f.keyword("then", depth+1, depth)
+		f.statements(ew.Consequences.Consequences, depth+1)
+	}
+	if rule.Else != nil {
+		f.keyword("else", depth+1, depth)
+		f.statements(rule.Else.Consequences, depth+1)
+	}
+	f.keyword("done", depth+1, depth)
+	f.opened = false
+}
+
+// expression emits a rule's expression with a line per disjunct, or a line per conjunct where there is only one.
+func (f *formatter) expression(e Expression, depth int) {
+	if len(e.Or) == 1 {
+		for n, coe := range e.Or[0].And {
+			f.line(coe.Pos.Offset, depth, prefixed(n, "and ", f.conditionOrExpression(coe)))
+		}
+		return
+	}
+	for n, or := range e.Or {
+		f.line(or.Pos.Offset, depth, prefixed(n, "or ", f.orExpression(or)))
+	}
+}
+
+func (f *formatter) inlineExpression(e Expression) string {
+	parts := make([]string, len(e.Or))
+	for n, or := range e.Or {
+		parts[n] = f.orExpression(or)
+	}
+	return strings.Join(parts, " or ")
+}
+
+func (f *formatter) orExpression(or OrExpression) string {
+	parts := make([]string, len(or.And))
+	for n, coe := range or.And {
+		parts[n] = f.conditionOrExpression(coe)
+	}
+	return strings.Join(parts, " and ")
+}
+
+func (f *formatter) conditionOrExpression(coe ConditionOrExpression) string {
+	switch {
+	case coe.Condition != nil && coe.Condition.ScalarCondition != nil:
+		c := coe.Condition.ScalarCondition
+		return fmt.Sprintf("%s %s %s", mixedValueSource(c.LeftValue), displayOperator(c.Op), mixedValueSource(c.RightValue))
+	case coe.Condition != nil && coe.Condition.ListCondition != nil:
+		return listConditionSource(*coe.Condition.ListCondition)
+	case coe.Condition != nil && coe.Condition.PresenceCondition != nil:
+		c := coe.Condition.PresenceCondition
+		return fmt.Sprintf("var(%s) is %s", c.Var, displayOperator(c.Op))
+	case coe.Expression != nil:
+		return "(" + f.inlineExpression(*coe.Expression) + ")"
+	case coe.Not != nil:
+		return "not " + f.conditionOrExpression(*coe.Not)
+	}
+	return ""
+}
+
+func (f *formatter) scoreChange(sc ScoreChange) string {
+	return fmt.Sprintf("score(%s) %s %s", sc.Score.Name, sc.Operator, sumSource(sc.Value))
+}
+
+// keyword emits a line holding a keyword which has no node of its own within the AST, locating it in the source such
+// that preceding comments are flushed at the depth of the block it closes.
+func (f *formatter) keyword(word string, inner, depth int) {
+	offset := f.cursor
+	for _, t := range f.tokens {
+		if t.Pos.Offset > f.cursor && t.Type == lexr.Symbols()["Ident"] && t.Value == word {
+			offset = t.Pos.Offset
+			break
+		}
+	}
+	f.flush(offset, inner, word != "then")
+	f.line(offset, depth, word)
+}
+
+// flush emits the comments preceding the source offset. Where blank is set, blank lines preceding the comments in
+// the source are carried over.
+func (f *formatter) flush(offset, depth int, blank bool) {
+	for len(f.comments) > 0 && f.comments[0].Pos.Offset < offset {
+		c := f.comments[0]
+		f.comments = f.comments[1:]
+		if f.trailing(c) && len(f.lines) > 0 && f.trailed != len(f.lines) {
+			f.lines[len(f.lines)-1] += " " + c.Value
+			f.trailed = len(f.lines)
+			continue
+		}
+		if blank && f.blankable() && f.gapBefore(c.Pos.Offset) {
+			f.lines = append(f.lines, "")
+		}
+		f.lines = append(f.lines, strings.Repeat(formatIndent, depth)+c.Value)
+		f.trailed = len(f.lines)
+		f.opened = false
+	}
+}
+
+func (f *formatter) line(offset, depth int, text string) {
+	f.flush(offset, depth, false)
+	f.opened = true
+	if offset > f.cursor {
+		f.cursor = offset
+	}
+	indent := strings.Repeat(formatIndent, depth)
+	for _, l := range strings.Split(text, "\n") {
+		f.lines = append(f.lines, indent+l)
+	}
+}
+
+// trailing reports whether the comment follows code on the same source line.
+func (f *formatter) trailing(c lexer.Token) bool {
+	prev, ok := f.tokenBefore(c.Pos.Offset)
+	return ok && prev.Type != lexr.Symbols()["Comment"] && prev.Pos.Line == c.Pos.Line
+}
+
+// gapBefore reports whether a blank line separates the token at the source offset from the token before it.
+func (f *formatter) gapBefore(offset int) bool {
+	prev, ok := f.tokenBefore(offset)
+	if !ok {
+		return false
+	}
+	n := sort.Search(len(f.tokens), func(i int) bool { return f.tokens[i].Pos.Offset >= offset })
+	if n == len(f.tokens) {
+		return false
+	}
+	return f.tokens[n].Pos.Line-prev.Pos.Line-strings.Count(prev.Value, "\n") > 1
+}
+
+func (f *formatter) tokenBefore(offset int) (lexer.Token, bool) {
+	n := sort.Search(len(f.tokens), func(i int) bool { return f.tokens[i].Pos.Offset >= offset })
+	if n == 0 {
+		return lexer.Token{}, false
+	}
+	return f.tokens[n-1], true
+}
+
+func (f *formatter) blankable() bool {
+	return len(f.lines) > 0 && f.lines[len(f.lines)-1] != "" && !f.opened
+}
+
+func prefixed(n int, prefix, s string) string {
+	if n == 0 {
+		return s
+	}
+	return prefix + s
+}
+
+func listConditionSource(c ListCondition) string {
+	items := make([]string, len(c.RightValues))
+	for n, mv := range c.RightValues {
+		items[n] = mixedValueSource(mv)
+	}
+	prefix := fmt.Sprintf("%s %s [", mixedValueSource(c.LeftValue), displayOperator(c.Op))
+	if inline := prefix + strings.Join(items, ", ") + "]"; len(inline) <= formatListWidth {
+		return inline
+	}
+	return prefix + "\n" + formatIndent + strings.Join(items, ",\n"+formatIndent) + "\n]"
+}
+
+func mixedValueSource(mv MixedValue) string {
+	switch {
+	case mv.Var != nil:
+		return fmt.Sprintf("var(%s)", *mv.Var)
+	case mv.String != nil:
+		return strconv.Quote(*mv.String)
+	case mv.Int != nil:
+		return strconv.Itoa(*mv.Int)
+	case mv.Float != nil:
+		return floatSource(*mv.Float)
+	case mv.Score != nil:
+		return fmt.Sprintf("score(%s)", mv.Score.Name)
+	case mv.Regexp != nil:
+		return "/" + *mv.Regexp + "/"
+	}
+	return ""
+}
+
+func sumSource(s Sum) string {
+	out := productSource(s.Left)
+	for _, so := range s.Right {
+		out += fmt.Sprintf(" %s %s", so.Operator, productSource(so.Product))
+	}
+	return out
+}
+
+func productSource(p Product) string {
+	out := numericValueSource(p.Left)
+	for _, po := range p.Right {
+		out += fmt.Sprintf(" %s %s", po.Operator, numericValueSource(po.Value))
+	}
+	return out
+}
+
+func numericValueSource(nv NumericValue) string {
+	switch {
+	case nv.Int != nil:
+		return strconv.Itoa(*nv.Int)
+	case nv.Float != nil:
+		return floatSource(*nv.Float)
+	case nv.Score != nil:
+		return fmt.Sprintf("score(%s)", nv.Score.Name)
+	case nv.Var != nil:
+		return fmt.Sprintf("var(%s)", *nv.Var)
+	case nv.Sum != nil:
+		return "(" + sumSource(*nv.Sum) + ")"
+	}
+	return ""
+}
+
+// floatSource renders a float such that it lexes as a float rather than an int.
+func floatSource(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
@@ -0,0 +1,211 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{
+			name: "score changes",
+			src:  "score(x)=1.0\nscore(y)  -= (score(x)+2)*var(n)-1\n",
+			expected: `score(x) = 1.0
+score(y) -= (score(x) + 2) * var(n) - 1
+`,
+		},
+		{
+			name: "rule with branches",
+			src: `rule "a"   when var(a)=="x" and not (var(b) in ["y","z"] or var(c)>=-2)
+then score(x)+=1 else when var(a) does not match /q/ then exit else score(y)=0.25 done`,
+			expected: `rule "a"
+when
+  var(a) == "x"
+  and not (var(b) in ["y", "z"] or var(c) >= -2)
+then
+  score(x) += 1
+else when
+  var(a) does not match /q/
+then
+  exit
+else
+  score(y) = 0.25
+done
+`,
+		},
+		{
+			name: "comments",
+			src: `# header
+
+score(x) = 1 # trailing
+when
+    # leading
+    var(a) contains "x"
+    or var(b) == 1
+then
+    when var(c) == "d" then score(x) += 1 done
+
+
+    # before done
+done
+# end
+`,
+			expected: `# header
+
+score(x) = 1 # trailing
+when
+  # leading
+  var(a) contains "x"
+  or var(b) == 1
+then
+  when
+    var(c) == "d"
+  then
+    score(x) += 1
+  done
+
+  # before done
+done
+# end
+`,
+		},
+		{
+			name: "case-insensitive operators",
+			src:  `when var(a) ==~ "x" or var(b) contains  ignoring case "y" or var(c) not in ignoring  case ["z"] then exit done`,
+			expected: `when
+  var(a) ==~ "x"
+  or var(b) contains ignoring case "y"
+  or var(c) not in ignoring case ["z"]
+then
+  exit
+done
+`,
+		},
+		{
+			name: "prefix and suffix operators",
+			src:  `when var(a) starts  with "x" and var(b) does not   end with "y" then exit done`,
+			expected: `when
+  var(a) starts with "x"
+  and var(b) does not end with "y"
+then
+  exit
+done
+`,
+		},
+		{
+			name: "presence conditions",
+			src:  `when var(a) is  set and var(b) is not   set or var(c) is empty then exit done`,
+			expected: `when
+  var(a) is set and var(b) is not set
+  or var(c) is empty
+then
+  exit
+done
+`,
+		},
+		{
+			name: "input block",
+			src: `# schema
+input {title:string,views:int
+  tags: list}
+
+when var(title) contains "x" then score(x) = var(views) done`,
+			expected: `# schema
+input { title: string, views: int, tags: list }
+
+when
+  var(title) contains "x"
+then
+  score(x) = var(views)
+done
+`,
+		},
+		{
+			name: "long input block",
+			src:  `input { title: string, body: string, author: string, views: int, shares: int, tags: list }`,
+			expected: `input {
+  title: string,
+  body: string,
+  author: string,
+  views: int,
+  shares: int,
+  tags: list
+}
+`,
+		},
+		{
+			name: "comments trailing a collapsed list",
+			src: `when var(a) in [ # c1
+  "x", # c2
+  "y" # c3
+] # c4
+then score(x) = 1 # c5
+score(y) = 2 # c6
+done`,
+			expected: `when
+  var(a) in ["x", "y"] # c1
+  # c2
+  # c3
+  # c4
+then
+  score(x) = 1 # c5
+  score(y) = 2 # c6
+done
+`,
+		},
+		{
+			name:     "empty source",
+			src:      "",
+			expected: "",
+		},
+		{
+			name: "long list",
+			src:  `when var(topic) in ["elections", "international relations", "national security", "economics"] then score(x) = 1 done`,
+			expected: `when
+  var(topic) in [
+    "elections",
+    "international relations",
+    "national security",
+    "economics"
+  ]
+then
+  score(x) = 1
+done
+`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			out, err := Format([]byte(tc.src))
+			require.NoError(tt, err)
+			assert.Equal(tt, tc.expected, string(out))
+
+			again, err := Format(out)
+			require.NoError(tt, err)
+			assert.Equal(tt, string(out), string(again), "formatting is not idempotent")
+
+			before, err := Parse(bytes.NewReader([]byte(tc.src)))
+			require.NoError(tt, err)
+			after, err := Parse(bytes.NewReader(out))
+			require.NoError(tt, err)
+			assert.Equal(tt, generate(before), generate(after), "formatting changed instructions")
+		})
+	}
+}
+
+func generate(root Root) []string {
+	ig := NewInstructionsGenerator()
+	ig.Generate(root)
+	var out []string
+	for _, in := range ig.Instructions() {
+		out = append(out, in.StringSlice()...)
+	}
+	return out
+}
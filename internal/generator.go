@@ -4,13 +4,15 @@ import (
 	"fmt"
 	"regexp"
 
+	"github.com/alecthomas/participle/lexer"
 	"github.com/pkg/errors"
 )
 
 type InstructionsGenerator struct {
 	buf         *InstructionsBuffer
 	scratchUsed map[ScratchPosition]bool
-	err         error
+	rule        string
+	errs        ErrorList
 }
 
 func NewInstructionsGenerator() *InstructionsGenerator {
@@ -36,23 +38,69 @@ func (ig *InstructionsGenerator) evaluateStatement(s Statement) {
 	case s.Rule != nil:
 		ig.evaluateRule(*s.Rule)
 	case s.Exit:
-		ig.buf.Append(Instruction{Operation: OperationExit})
+		ig.buf.Append(Instruction{Operation: OperationExit, Pos: s.Pos})
 	default:
-		ig.setErr(fmt.Errorf("could not resolve score change or rule from %+v", s))
+		ig.addErr(s.Pos, fmt.Errorf("could not resolve score change or rule from %+v", s))
 	}
 }
 
 func (ig *InstructionsGenerator) evaluateRule(rule Rule) {
+	if rule.Name != nil {
+		defer ig.nameRule(*rule.Name, ig.buf.Head())()
+	}
+	first := ElseWhen{Pos: rule.Pos, Expression: rule.Expression, Consequences: rule.Consequences}
+	branches := append([]ElseWhen{first}, rule.ElseWhens...)
+	var exits []int
+	for n, branch := range branches {
+		last := n == len(branches)-1 && rule.Else == nil
+		if pos, ok := ig.evaluateBranch(branch, !last); ok {
+			exits = append(exits, pos)
+		}
+	}
+	if rule.Else != nil {
+		ig.evaluateConsequences(*rule.Else)
+	}
+	for _, p := range exits {
+		ig.buf.Replace(p, Instruction{
+			Operation: OperationJump,
+			Operand1:  InstructionPositionOperand{Pos: ig.buf.Head()},
+			Pos:       rule.Pos,
+		})
+	}
+}
+
+// nameRule sets the current rule name for the duration of the rule's generation, and returns a function which labels
+// the instructions generated from start onwards with it before restoring the enclosing rule name. Instructions
+// already labelled by a nested rule retain that label.
+func (ig *InstructionsGenerator) nameRule(name string, start int) func() {
+	enclosing := ig.rule
+	ig.rule = name
+	return func() {
+		for pos := start; pos < ig.buf.Head(); pos++ {
+			ig.buf.Label(pos, name)
+		}
+		ig.rule = enclosing
+	}
+}
+
+// evaluateBranch generates a single conditional branch of a rule. When exit is set, space for a jump beyond the
+// remaining branches is reserved after the consequences, and its position is returned for later replacement.
+func (ig *InstructionsGenerator) evaluateBranch(branch ElseWhen, exit bool) (exitPos int, ok bool) {
 	scratch := ig.allocateScratchPosition()
-	ig.evaluateExpression(rule.Expression, scratch)
+	ig.evaluateExpression(branch.Expression, scratch)
 	pos := ig.buf.Reserve()
-	ig.evaluateConsequences(rule.Consequences)
+	ig.evaluateConsequences(branch.Consequences)
+	if exit {
+		exitPos, ok = ig.buf.Reserve(), true
+	}
 	ig.buf.Replace(pos, Instruction{
 		Operation: OperationJumpIfZero,
 		Operand1:  ScratchOperand{Pos: scratch},
 		Operand2:  InstructionPositionOperand{Pos: ig.buf.Head()},
+		Pos:       branch.Pos,
 	})
 	ig.freeScratchPosition(scratch)
+	return
 }
 
 func (ig *InstructionsGenerator) evaluateExpression(e Expression, res ScratchPosition) {
@@ -71,6 +119,7 @@ func (ig *InstructionsGenerator) evaluateExpression(e Expression, res ScratchPos
 				Ret:       res,
 				Operand1:  ScratchOperand{Pos: scratch},
 				Operand2:  InstructionPositionOperand{Pos: ig.buf.Head()},
+				Pos:       e.Pos,
 			})
 		}
 	} else {
@@ -94,12 +143,14 @@ func (ig *InstructionsGenerator) evaluateOrExpression(or OrExpression, res Scrat
 				Ret:       res,
 				Operand1:  ScratchOperand{Pos: scratch},
 				Operand2:  InstructionPositionOperand{Pos: ig.buf.Head()},
+				Pos:       or.Pos,
 			})
 		}
 		ig.buf.Append(Instruction{
 			Operation: OperationNegate,
 			Ret:       res,
 			Operand1:  ScratchOperand{Pos: res},
+			Pos:       or.Pos,
 		})
 	} else {
 		ig.evaluateConditionOrExpression(or.And[0], res)
@@ -112,8 +163,16 @@ func (ig *InstructionsGenerator) evaluateConditionOrExpression(coe ConditionOrEx
 		ig.evaluateCondition(*coe.Condition, res)
 	case coe.Expression != nil:
 		ig.evaluateExpression(*coe.Expression, res)
+	case coe.Not != nil:
+		ig.evaluateConditionOrExpression(*coe.Not, res)
+		ig.buf.Append(Instruction{
+			Operation: OperationNegate,
+			Ret:       res,
+			Operand1:  ScratchOperand{Pos: res},
+			Pos:       coe.Pos,
+		})
 	default:
-		ig.setErr(fmt.Errorf("could not resolve condition or expression from %+v", coe))
+		ig.addErr(coe.Pos, fmt.Errorf("could not resolve condition or expression from %+v", coe))
 	}
 }
 
@@ -123,54 +182,68 @@ func (ig *InstructionsGenerator) evaluateCondition(cond Condition, res ScratchPo
 		ig.evaluateScalarCondition(*cond.ScalarCondition, res)
 	case cond.ListCondition != nil:
 		ig.evaluateListCondition(*cond.ListCondition, res)
+	case cond.PresenceCondition != nil:
+		ig.evaluatePresenceCondition(*cond.PresenceCondition, res)
 	default:
-		ig.setErr(fmt.Errorf("could not resolve scalar or list condition from %+v", cond))
+		ig.addErr(cond.Pos, fmt.Errorf("could not resolve scalar, list or presence condition from %+v", cond))
 	}
 }
 
 func (ig *InstructionsGenerator) evaluateScalarCondition(cond ScalarCondition, res ScratchPosition) {
-	op, err := operationFromEqualityOperator(cond.Op)
-	if err != nil {
-		ig.setErr(errors.Wrap(err, "failed to map condition operation"))
-		return
+	op, opErr := operationFromEqualityOperator(cond.Op)
+	if opErr != nil {
+		ig.addErr(cond.Pos, errors.Wrap(opErr, "failed to map condition operation"))
 	}
-	operand1, err := operandFromMixedValue(cond.LeftValue)
-	if err != nil {
-		ig.setErr(errors.Wrap(err, "failed to map first operand"))
-		return
+	operand1, err1 := operandFromMixedValue(cond.LeftValue)
+	if err1 != nil {
+		ig.addErr(cond.LeftValue.Pos, errors.Wrap(err1, "failed to map first operand"))
 	}
-	operand2, err := operandFromMixedValue(cond.RightValue)
-	if err != nil {
-		ig.setErr(errors.Wrap(err, "failed to map second operand"))
+	operand2, err2 := operandFromMixedValue(cond.RightValue)
+	if err2 != nil {
+		ig.addErr(cond.RightValue.Pos, errors.Wrap(err2, "failed to map second operand"))
+	}
+	if opErr != nil || err1 != nil || err2 != nil {
 		return
 	}
+	if op.IsFold() {
+		operand1, operand2 = foldOperand(operand1), foldOperand(operand2)
+	}
 	ig.buf.Append(Instruction{
 		Operation: op,
 		Ret:       res,
 		Operand1:  operand1,
 		Operand2:  operand2,
+		Pos:       cond.Pos,
 	})
 }
 
 func (ig *InstructionsGenerator) evaluateListCondition(cond ListCondition, res ScratchPosition) {
 	operand1, err := operandFromMixedValue(cond.LeftValue)
 	if err != nil {
-		ig.setErr(errors.Wrap(err, "failed to first operand"))
-		return
+		ig.addErr(cond.LeftValue.Pos, errors.Wrap(err, "failed to first operand"))
+	}
+	op := OperationIsEqual
+	if cond.Op == "inignoringcase" || cond.Op == "notinignoringcase" {
+		op = OperationIsEqualFold
+		operand1 = foldOperand(operand1)
 	}
 	reserved := map[int]ScratchPosition{}
 	for _, mv := range cond.RightValues {
 		operand2, err := operandFromMixedValue(mv)
 		if err != nil {
-			ig.setErr(errors.Wrap(err, "failed to list value operand"))
-			return
+			ig.addErr(mv.Pos, errors.Wrap(err, "failed to list value operand"))
+			continue
+		}
+		if op.IsFold() {
+			operand2 = foldOperand(operand2)
 		}
 		inner := ig.allocateScratchPosition()
 		ig.buf.Append(Instruction{
-			Operation: OperationIsEqual,
+			Operation: op,
 			Ret:       inner,
 			Operand1:  operand1,
 			Operand2:  operand2,
+			Pos:       cond.Pos,
 		})
 		pos := ig.buf.Reserve()
 		reserved[pos] = inner
@@ -182,17 +255,33 @@ func (ig *InstructionsGenerator) evaluateListCondition(cond ListCondition, res S
 			Ret:       res,
 			Operand1:  ScratchOperand{Pos: scratch},
 			Operand2:  InstructionPositionOperand{Pos: ig.buf.Head()},
+			Pos:       cond.Pos,
 		})
 	}
-	if cond.Op == "notin" {
+	if cond.Op == "notin" || cond.Op == "notinignoringcase" {
 		ig.buf.Append(Instruction{
 			Operation: OperationNegate,
 			Ret:       res,
 			Operand1:  ScratchOperand{Pos: res},
+			Pos:       cond.Pos,
 		})
 	}
 }
 
+func (ig *InstructionsGenerator) evaluatePresenceCondition(cond PresenceCondition, res ScratchPosition) {
+	op, err := operationFromPresenceOperator(cond.Op)
+	if err != nil {
+		ig.addErr(cond.Pos, errors.Wrap(err, "failed to map presence operation"))
+		return
+	}
+	ig.buf.Append(Instruction{
+		Operation: op,
+		Ret:       res,
+		Operand1:  VarOperand{Name: cond.Var},
+		Pos:       cond.Pos,
+	})
+}
+
 func (ig *InstructionsGenerator) evaluateConsequences(cons Consequences) {
 	for _, s := range cons.Consequences {
 		ig.evaluateStatement(s)
@@ -202,20 +291,83 @@ func (ig *InstructionsGenerator) evaluateConsequences(cons Consequences) {
 func (ig *InstructionsGenerator) evaluateScoreChange(sc ScoreChange) {
 	op, err := operationFromScoreChange(sc)
 	if err != nil {
-		ig.setErr(errors.Wrap(err, "failed to map score change operation"))
+		ig.addErr(sc.Pos, errors.Wrap(err, "failed to map score change operation"))
 		return
 	}
 	operand1 := ScoreOperand{Name: sc.Score.Name}
-	operand2, err := operandFromIntValue(sc.Value)
-	if err != nil {
-		ig.setErr(errors.Wrap(err, "failed to map second operand"))
-		return
-	}
+	operand2, scratch := ig.sumOperand(sc.Value)
 	ig.buf.Append(Instruction{
 		Operation: op,
 		Operand1:  operand1,
 		Operand2:  operand2,
+		Pos:       sc.Pos,
+	})
+	ig.freeScratchPosition(scratch)
+}
+
+// sumOperand resolves an arithmetic sum into an operand. Where the sum cannot be represented by a single operand,
+// instructions computing it are generated, and the scratch position holding the result is returned. The caller is
+// responsible for freeing this position once the operand has been consumed.
+func (ig *InstructionsGenerator) sumOperand(s Sum) (Operand, ScratchPosition) {
+	left, scratch := ig.productOperand(s.Left)
+	for _, so := range s.Right {
+		op, err := operationFromArithmeticOperator(so.Operator)
+		if err != nil {
+			ig.addErr(so.Pos, errors.Wrap(err, "failed to map arithmetic operation"))
+		}
+		right, inner := ig.productOperand(so.Product)
+		left, scratch = ig.arithmetic(op, left, right, scratch, so.Pos)
+		ig.freeScratchPosition(inner)
+	}
+	return left, scratch
+}
+
+func (ig *InstructionsGenerator) productOperand(p Product) (Operand, ScratchPosition) {
+	left, scratch := ig.numericValueOperand(p.Left)
+	for _, po := range p.Right {
+		op, err := operationFromArithmeticOperator(po.Operator)
+		if err != nil {
+			ig.addErr(po.Pos, errors.Wrap(err, "failed to map arithmetic operation"))
+		}
+		right, inner := ig.numericValueOperand(po.Value)
+		left, scratch = ig.arithmetic(op, left, right, scratch, po.Pos)
+		ig.freeScratchPosition(inner)
+	}
+	return left, scratch
+}
+
+func (ig *InstructionsGenerator) numericValueOperand(nv NumericValue) (Operand, ScratchPosition) {
+	switch {
+	case nv.Int != nil:
+		return IntOperand{Value: *nv.Int}, 0
+	case nv.Float != nil:
+		return FloatOperand{Value: *nv.Float}, 0
+	case nv.Score != nil:
+		return ScoreOperand{Name: nv.Score.Name}, 0
+	case nv.Var != nil:
+		return VarOperand{Name: *nv.Var}, 0
+	case nv.Sum != nil:
+		return ig.sumOperand(*nv.Sum)
+	default:
+		ig.addErr(nv.Pos, fmt.Errorf("unresolvable numeric value %+v", nv))
+		return nil, 0
+	}
+}
+
+func (ig *InstructionsGenerator) arithmetic(
+	op Operation, left, right Operand, scratch ScratchPosition, pos lexer.Position,
+) (Operand, ScratchPosition) {
+	if scratch == 0 {
+		scratch = ig.allocateScratchPosition()
+	}
+	ig.buf.Append(Instruction{
+		Operation: op,
+		Ret:       scratch,
+		Operand1:  left,
+		Operand2:  right,
+		Pos:       pos,
 	})
+	return ScratchOperand{Pos: scratch}, scratch
 }
 
 // nolint:gocyclo
@@ -241,12 +393,40 @@ func operationFromEqualityOperator(s string) (op Operation, err error) {
 		op = OperationMatches
 	case "doesnotmatch":
 		op = OperationDoesNotMatch
+	case "==~":
+		op = OperationIsEqualFold
+	case "containsignoringcase":
+		op = OperationContainsFold
+	case "doesnotcontainignoringcase":
+		op = OperationDoesNotContainFold
+	case "startswith":
+		op = OperationStartsWith
+	case "doesnotstartwith":
+		op = OperationDoesNotStartWith
+	case "endswith":
+		op = OperationEndsWith
+	case "doesnotendwith":
+		op = OperationDoesNotEndWith
 	default:
 		err = fmt.Errorf("unknown operation %s", s)
 	}
 	return
 }
 
+func operationFromPresenceOperator(s string) (op Operation, err error) {
+	switch s {
+	case "set":
+		op = OperationIsSet
+	case "notset":
+		op = OperationIsNotSet
+	case "empty":
+		op = OperationIsEmpty
+	default:
+		err = fmt.Errorf("unknown presence operation %s", s)
+	}
+	return
+}
+
 func operandFromMixedValue(mv MixedValue) (op Operand, err error) {
 	switch {
 	case mv.Var != nil:
@@ -255,6 +435,8 @@ func operandFromMixedValue(mv MixedValue) (op Operand, err error) {
 		op = StringOperand{Value: *mv.String}
 	case mv.Int != nil:
 		op = IntOperand{Value: *mv.Int}
+	case mv.Float != nil:
+		op = FloatOperand{Value: *mv.Float}
 	case mv.Score != nil:
 		op = ScoreOperand{Name: mv.Score.Name}
 	case mv.Regexp != nil:
@@ -273,14 +455,18 @@ func buildRegexpOperand(s string) (RegexpOperand, error) {
 	return RegexpOperand{Value: rg}, nil
 }
 
-func operandFromIntValue(iv IntValue) (op Operand, err error) {
-	switch {
-	case iv.Int != nil:
-		op = IntOperand{Value: *iv.Int}
-	case iv.Score != nil:
-		op = ScoreOperand{Name: iv.Score.Name}
+func operationFromArithmeticOperator(s string) (op Operation, err error) {
+	switch s {
+	case "+":
+		op = OperationAdd
+	case "-":
+		op = OperationSub
+	case "*":
+		op = OperationMul
+	case "/":
+		op = OperationDiv
 	default:
-		err = fmt.Errorf("unresolvable int value %+v", iv)
+		err = fmt.Errorf("unknown arithmetic operation %s", s)
 	}
 	return
 }
@@ -293,6 +479,12 @@ func operationFromScoreChange(sc ScoreChange) (op Operation, err error) {
 		op = OperationSubScore
 	case "=":
 		op = OperationSetScore
+	case "*=":
+		op = OperationMulScore
+	case "/=":
+		op = OperationDivScore
+	case "%=":
+		op = OperationModScore
 	default:
 		err = fmt.Errorf("unknown operation %+v", sc)
 	}
@@ -317,12 +509,10 @@ func (ig *InstructionsGenerator) Instructions() []Instruction {
 	return ig.buf.Instructions()
 }
 
-func (ig *InstructionsGenerator) Err() error {
-	return ig.err
+func (ig *InstructionsGenerator) Errors() ErrorList {
+	return ig.errs
 }
 
-func (ig *InstructionsGenerator) setErr(err error) {
-	if ig.err == nil {
-		ig.err = err
-	}
+func (ig *InstructionsGenerator) addErr(pos lexer.Position, err error) {
+	ig.errs = append(ig.errs, &Error{Pos: pos, Rule: ig.rule, Err: err})
 }
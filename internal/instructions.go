@@ -3,6 +3,9 @@ package internal
 import (
 	"fmt"
 	"regexp"
+	"strconv"
+
+	"github.com/alecthomas/participle/lexer"
 )
 
 type Operation int8
@@ -21,11 +24,29 @@ const (
 	OperationDoesNotMatch
 	OperationJumpIfZero
 	OperationJumpIfNotZero
+	OperationJump
 	OperationAddScore
 	OperationSubScore
 	OperationSetScore
+	OperationMulScore
+	OperationDivScore
+	OperationModScore
 	OperationNegate
 	OperationExit
+	OperationAdd
+	OperationSub
+	OperationMul
+	OperationDiv
+	OperationIsEqualFold
+	OperationContainsFold
+	OperationDoesNotContainFold
+	OperationStartsWith
+	OperationDoesNotStartWith
+	OperationEndsWith
+	OperationDoesNotEndWith
+	OperationIsSet
+	OperationIsNotSet
+	OperationIsEmpty
 )
 
 var operationToStringMap = map[Operation]string{
@@ -42,17 +63,62 @@ var operationToStringMap = map[Operation]string{
 	OperationDoesNotMatch:         "DOES_NOT_MATCH",
 	OperationJumpIfZero:           "JUMP_IF_ZERO",
 	OperationJumpIfNotZero:        "JUMP_IF_NOT_ZERO",
+	OperationJump:                 "JUMP",
 	OperationAddScore:             "ADD_SCORE",
 	OperationSubScore:             "SUB_SCORE",
 	OperationSetScore:             "SET_SCORE",
+	OperationMulScore:             "MUL_SCORE",
+	OperationDivScore:             "DIV_SCORE",
+	OperationModScore:             "MOD_SCORE",
 	OperationNegate:               "NEGATE",
 	OperationExit:                 "EXIT",
+	OperationAdd:                  "ADD",
+	OperationSub:                  "SUB",
+	OperationMul:                  "MUL",
+	OperationDiv:                  "DIV",
+	OperationIsEqualFold:          "IS_EQUAL_FOLD",
+	OperationContainsFold:         "CONTAINS_FOLD",
+	OperationDoesNotContainFold:   "DOES_NOT_CONTAIN_FOLD",
+	OperationStartsWith:           "STARTS_WITH",
+	OperationDoesNotStartWith:     "DOES_NOT_START_WITH",
+	OperationEndsWith:             "ENDS_WITH",
+	OperationDoesNotEndWith:       "DOES_NOT_END_WITH",
+	OperationIsSet:                "IS_SET",
+	OperationIsNotSet:             "IS_NOT_SET",
+	OperationIsEmpty:              "IS_EMPTY",
 }
 
 func (o Operation) String() string {
 	return operationToStringMap[o]
 }
 
+func (o Operation) IsCondition() bool {
+	switch o {
+	case OperationIsEqual, OperationIsNotEqual, OperationIsGreaterThan, OperationIsGreaterThanOrEqual,
+		OperationIsLessThan, OperationIsLessThanOrEqual, OperationContains, OperationDoesNotContain,
+		OperationMatches, OperationDoesNotMatch, OperationIsEqualFold, OperationContainsFold,
+		OperationDoesNotContainFold, OperationStartsWith, OperationDoesNotStartWith, OperationEndsWith,
+		OperationDoesNotEndWith, OperationIsSet, OperationIsNotSet, OperationIsEmpty:
+		return true
+	}
+	return false
+}
+
+// IsPresence reports whether the operation tests the presence of a variable. Such operations take a single operand.
+func (o Operation) IsPresence() bool {
+	return o == OperationIsSet || o == OperationIsNotSet || o == OperationIsEmpty
+}
+
+// IsFold reports whether the operation compares strings case-insensitively. String literal operands of such
+// operations are case folded at compile time.
+func (o Operation) IsFold() bool {
+	return o == OperationIsEqualFold || o == OperationContainsFold || o == OperationDoesNotContainFold
+}
+
+func (o Operation) IsScoreChange() bool {
+	return o >= OperationAddScore && o <= OperationModScore
+}
+
 type Operand interface {
 	String() string
 }
@@ -81,6 +147,14 @@ func (io IntOperand) String() string {
 	return fmt.Sprintf("int(%d)", io.Value)
 }
 
+type FloatOperand struct {
+	Value float64
+}
+
+func (fo FloatOperand) String() string {
+	return fmt.Sprintf("float(%s)", strconv.FormatFloat(fo.Value, 'f', -1, 64))
+}
+
 type StringOperand struct {
 	Value string
 }
@@ -124,6 +198,9 @@ type Instruction struct {
 	Ret       ScratchPosition
 	Operand1  Operand
 	Operand2  Operand
+	Pos       lexer.Position
+	// Rule holds the name of the rule from which the instruction was generated, if the rule was named.
+	Rule string
 }
 
 func (i Instruction) StringSlice() []string {
@@ -141,6 +218,22 @@ func (i Instruction) StringSlice() []string {
 	return parts
 }
 
+// ScratchSize returns the number of scratch positions required to execute the instructions.
+func ScratchSize(ins []Instruction) int {
+	var max ScratchPosition
+	for _, in := range ins {
+		if in.Ret > max {
+			max = in.Ret
+		}
+		for _, op := range []Operand{in.Operand1, in.Operand2} {
+			if so, ok := op.(ScratchOperand); ok && so.Pos > max {
+				max = so.Pos
+			}
+		}
+	}
+	return int(max) + 1
+}
+
 type InstructionsBuffer struct {
 	ins []Instruction
 }
@@ -163,6 +256,13 @@ func (i *InstructionsBuffer) Replace(pos int, in Instruction) {
 	i.ins[pos] = in
 }
 
+// Label attributes the instruction at pos to the named rule, unless it is already attributed to one.
+func (i *InstructionsBuffer) Label(pos int, rule string) {
+	if i.ins[pos].Rule == "" {
+		i.ins[pos].Rule = rule
+	}
+}
+
 func (i *InstructionsBuffer) Instructions() []Instruction {
 	return i.ins
 }
@@ -46,6 +46,15 @@ func TestInstruction_StringSlice(t *testing.T) {
 			},
 			expected: []string{"NOOP", "$1", "int(1)", "int(2)"},
 		},
+		{
+			name: "float operand",
+			ins: Instruction{
+				Operation: OperationAddScore,
+				Operand1:  ScoreOperand{Name: "x"},
+				Operand2:  FloatOperand{Value: 0.35},
+			},
+			expected: []string{"ADD_SCORE", "", "score(x)", "float(0.35)"},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(tt *testing.T) {
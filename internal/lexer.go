@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/alecthomas/participle/lexer"
+)
+
+// contextualDefinition wraps a lexer definition to resolve tokens that are ambiguous without context. Where a
+// numeric operand has just been seen, a leading "/" is division rather than the start of a regexp, and a leading
+// "+" or "-" is an arithmetic operator rather than the sign of an int or float.
+type contextualDefinition struct {
+	lexer.Definition
+}
+
+func (d contextualDefinition) Lex(r io.Reader) (lexer.Lexer, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	cl := &contextualLexer{
+		def:     d.Definition,
+		symbols: d.Symbols(),
+		src:     string(b),
+		base:    lexer.Position{Filename: lexer.NameOfReader(r), Line: 1, Column: 1},
+	}
+	if err := cl.restart(); err != nil {
+		return nil, err
+	}
+	return cl, nil
+}
+
+type contextualLexer struct {
+	def     lexer.Definition
+	symbols map[string]rune
+	src     string
+	base    lexer.Position
+	cur     lexer.Lexer
+	prev    lexer.Token
+}
+
+func (cl *contextualLexer) Next() (lexer.Token, error) {
+	t, err := cl.cur.Next()
+	if err != nil {
+		if le, ok := err.(*lexer.Error); ok {
+			le.Tok.Pos = cl.position(le.Tok.Pos)
+		}
+		return t, err
+	}
+	t.Pos = cl.position(t.Pos)
+	if cl.isAmbiguous(t) && cl.followsNumericOperand() {
+		t = lexer.Token{Type: cl.symbols["Punct"], Value: t.Value[:1], Pos: t.Pos}
+		cl.base = lexer.Position{
+			Filename: t.Pos.Filename,
+			Offset:   t.Pos.Offset + 1,
+			Line:     t.Pos.Line,
+			Column:   t.Pos.Column + 1,
+		}
+		if err := cl.restart(); err != nil {
+			return t, err
+		}
+	}
+	if t.Type != cl.symbols["Whitespace"] && t.Type != cl.symbols["Comment"] {
+		cl.prev = t
+	}
+	return t, nil
+}
+
+func (cl *contextualLexer) isAmbiguous(t lexer.Token) bool {
+	switch t.Type {
+	case cl.symbols["Regexp"]:
+		return true
+	case cl.symbols["Int"], cl.symbols["Float"]:
+		return strings.HasPrefix(t.Value, "-") || strings.HasPrefix(t.Value, "+")
+	}
+	return false
+}
+
+func (cl *contextualLexer) followsNumericOperand() bool {
+	return cl.prev.Type == cl.symbols["Int"] || cl.prev.Type == cl.symbols["Float"] || (cl.prev.Type == cl.symbols["Punct"] && cl.prev.Value == ")")
+}
+
+func (cl *contextualLexer) restart() (err error) {
+	cl.cur, err = cl.def.Lex(strings.NewReader(cl.src[cl.base.Offset:]))
+	return
+}
+
+// position translates a position relative to the current underlying lexer into one relative to the source.
+func (cl *contextualLexer) position(p lexer.Position) lexer.Position {
+	if p.Line == 1 {
+		p.Column += cl.base.Column - 1
+	}
+	p.Line += cl.base.Line - 1
+	p.Offset += cl.base.Offset
+	p.Filename = cl.base.Filename
+	return p
+}
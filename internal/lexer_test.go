@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/participle/lexer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextualLexer(t *testing.T) {
+	symbols := lexer.SymbolsByRune(lexr)
+	testCases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "regexp",
+			input:    `"a" matches /a/b/`,
+			expected: []string{`String:"a"`, "Ident:matches", "Regexp:/a/", "Ident:b", "Punct:/"},
+		},
+		{
+			name:     "division",
+			input:    "4 / 2 /1",
+			expected: []string{"Int:4", "Punct:/", "Int:2", "Punct:/", "Int:1"},
+		},
+		{
+			name:     "division after parentheses",
+			input:    "(1)/ 2",
+			expected: []string{"Punct:(", "Int:1", "Punct:)", "Punct:/", "Int:2"},
+		},
+		{
+			name:     "signed int",
+			input:    "> -1",
+			expected: []string{"Punct:>", "Int:-1"},
+		},
+		{
+			name:     "subtraction",
+			input:    "score(a)-1+2",
+			expected: []string{"Ident:score", "Punct:(", "Ident:a", "Punct:)", "Punct:-", "Int:1", "Punct:+", "Int:2"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			lx, err := lexr.Lex(strings.NewReader(tc.input))
+			require.NoError(tt, err)
+			tokens, err := lexer.ConsumeAll(lx)
+			require.NoError(tt, err)
+			var actual []string
+			for _, tok := range tokens {
+				if sym := symbols[tok.Type]; sym != "Whitespace" && sym != "EOF" {
+					actual = append(actual, sym+":"+tok.Value)
+				}
+			}
+			assert.Equal(tt, tc.expected, actual)
+		})
+	}
+}
+
+func TestContextualLexer_Positions(t *testing.T) {
+	lx, err := lexr.Lex(strings.NewReader("1\n 2 /3"))
+	require.NoError(t, err)
+	tokens, err := lexer.ConsumeAll(lx)
+	require.NoError(t, err)
+	last := tokens[len(tokens)-2]
+	assert.Equal(t, "3", last.Value)
+	assert.Equal(t, lexer.Position{Offset: 6, Line: 2, Column: 5}, last.Pos)
+}
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// limitCheckInterval is the number of instructions executed between checks of the context and deadline, which are
+// comparatively expensive to perform.
+const limitCheckInterval = 256
+
+var (
+	ErrInstructionLimitExceeded = errors.New("instruction limit exceeded")
+	ErrTimeLimitExceeded        = errors.New("time limit exceeded")
+)
+
+// Limits bounds the cost of execution. The zero value imposes no bounds.
+type Limits struct {
+	// Context, when set, aborts execution with the context's error once done.
+	Context context.Context
+	// MaxInstructions, when positive, aborts execution with ErrInstructionLimitExceeded once exceeded.
+	MaxInstructions int
+	// Deadline, when set, aborts execution with ErrTimeLimitExceeded once passed.
+	Deadline time.Time
+}
+
+// check verifies that execution of the count-th instruction is within the limits.
+func (l Limits) check(count int) error {
+	if l.MaxInstructions > 0 && count > l.MaxInstructions {
+		return ErrInstructionLimitExceeded
+	}
+	if count%limitCheckInterval != 1 {
+		return nil
+	}
+	if l.Context != nil {
+		if err := l.Context.Err(); err != nil {
+			return err
+		}
+	}
+	if !l.Deadline.IsZero() && time.Now().After(l.Deadline) {
+		return ErrTimeLimitExceeded
+	}
+	return nil
+}
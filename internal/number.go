@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"errors"
+	"math"
+	"strconv"
+)
+
+// Number is a numeric value as held in scores and scratch positions. Ints remain ints until combined with a float,
+// such that arithmetic involving ints alone (integer division in particular) is unaffected by float support.
+type Number struct {
+	float bool
+	i     int
+	f     float64
+}
+
+var (
+	errDivisionByZero = errors.New("division by zero")
+	errModuloByZero   = errors.New("modulo by zero")
+)
+
+func IntNumber(n int) Number {
+	return Number{i: n}
+}
+
+func FloatNumber(f float64) Number {
+	return Number{float: true, f: f}
+}
+
+func (n Number) IsFloat() bool {
+	return n.float
+}
+
+// Int returns the number as an int, truncating any fractional part.
+func (n Number) Int() int {
+	if n.float {
+		return int(n.f)
+	}
+	return n.i
+}
+
+func (n Number) Float() float64 {
+	if n.float {
+		return n.f
+	}
+	return float64(n.i)
+}
+
+func (n Number) IsZero() bool {
+	if n.float {
+		return n.f == 0
+	}
+	return n.i == 0
+}
+
+func (n Number) Add(o Number) Number {
+	if n.float || o.float {
+		return FloatNumber(n.Float() + o.Float())
+	}
+	return IntNumber(n.i + o.i)
+}
+
+func (n Number) Sub(o Number) Number {
+	if n.float || o.float {
+		return FloatNumber(n.Float() - o.Float())
+	}
+	return IntNumber(n.i - o.i)
+}
+
+func (n Number) Mul(o Number) Number {
+	if n.float || o.float {
+		return FloatNumber(n.Float() * o.Float())
+	}
+	return IntNumber(n.i * o.i)
+}
+
+func (n Number) Div(o Number) (Number, error) {
+	if o.IsZero() {
+		return Number{}, errDivisionByZero
+	}
+	if n.float || o.float {
+		return FloatNumber(n.Float() / o.Float()), nil
+	}
+	return IntNumber(n.i / o.i), nil
+}
+
+func (n Number) Mod(o Number) (Number, error) {
+	if o.IsZero() {
+		return Number{}, errModuloByZero
+	}
+	if n.float || o.float {
+		return FloatNumber(math.Mod(n.Float(), o.Float())), nil
+	}
+	return IntNumber(n.i % o.i), nil
+}
+
+// Compare returns -1, 0 or 1 where the number is less than, equal to or greater than the other.
+func (n Number) Compare(o Number) int {
+	if n.float || o.float {
+		a, b := n.Float(), o.Float()
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	}
+	switch {
+	case n.i < o.i:
+		return -1
+	case n.i > o.i:
+		return 1
+	}
+	return 0
+}
+
+func (n Number) String() string {
+	if n.float {
+		return strconv.FormatFloat(n.f, 'f', -1, 64)
+	}
+	return strconv.Itoa(n.i)
+}
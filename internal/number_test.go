@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumber_Arithmetic(t *testing.T) {
+	testCases := []struct {
+		name     string
+		actual   Number
+		expected Number
+	}{
+		{name: "int add", actual: IntNumber(1).Add(IntNumber(2)), expected: IntNumber(3)},
+		{name: "mixed add", actual: IntNumber(1).Add(FloatNumber(0.5)), expected: FloatNumber(1.5)},
+		{name: "int sub", actual: IntNumber(1).Sub(IntNumber(2)), expected: IntNumber(-1)},
+		{name: "float sub", actual: FloatNumber(1.5).Sub(FloatNumber(0.5)), expected: FloatNumber(1)},
+		{name: "int mul", actual: IntNumber(3).Mul(IntNumber(2)), expected: IntNumber(6)},
+		{name: "mixed mul", actual: FloatNumber(0.5).Mul(IntNumber(3)), expected: FloatNumber(1.5)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			assert.Equal(tt, tc.expected, tc.actual)
+		})
+	}
+}
+
+func TestNumber_Div(t *testing.T) {
+	n, err := IntNumber(7).Div(IntNumber(2))
+	assert.NoError(t, err)
+	assert.Equal(t, IntNumber(3), n)
+
+	n, err = IntNumber(7).Div(FloatNumber(2))
+	assert.NoError(t, err)
+	assert.Equal(t, FloatNumber(3.5), n)
+
+	_, err = IntNumber(7).Div(FloatNumber(0))
+	assert.EqualError(t, err, "division by zero")
+}
+
+func TestNumber_Mod(t *testing.T) {
+	n, err := IntNumber(7).Mod(IntNumber(4))
+	assert.NoError(t, err)
+	assert.Equal(t, IntNumber(3), n)
+
+	n, err = FloatNumber(7.5).Mod(IntNumber(2))
+	assert.NoError(t, err)
+	assert.Equal(t, FloatNumber(1.5), n)
+
+	_, err = IntNumber(7).Mod(IntNumber(0))
+	assert.EqualError(t, err, "modulo by zero")
+}
+
+func TestNumber_Compare(t *testing.T) {
+	assert.Equal(t, -1, IntNumber(1).Compare(IntNumber(2)))
+	assert.Equal(t, 0, IntNumber(2).Compare(FloatNumber(2)))
+	assert.Equal(t, 1, FloatNumber(2.5).Compare(IntNumber(2)))
+}
+
+func TestNumber_Int(t *testing.T) {
+	assert.Equal(t, 2, FloatNumber(2.9).Int())
+	assert.Equal(t, -2, FloatNumber(-2.9).Int())
+	assert.Equal(t, 5, IntNumber(5).Int())
+}
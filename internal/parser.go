@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"errors"
 	"io"
 
 	"github.com/alecthomas/participle"
@@ -9,18 +10,19 @@ import (
 )
 
 var (
-	lexr = lexer.Must(ebnf.New(`
+	lexr = contextualDefinition{lexer.Must(ebnf.New(`
 		Comment = "#" { "\u0000"…"\uffff"-"\n" } .
 		Ident = (alpha | "_") { "_" | alpha | digit } .
 		String = "\"" { "\u0000"…"\uffff"-"\""-"\\" | "\\" any } "\"" .
 		Regexp = "/" { "\u0000"…"\uffff"-"/"-"\\" | "\\" any } "/" .
+		Float = [ "-" | "+" ] digit { digit } "." digit { digit } .
 		Int = [ "-" | "+" ] digit { digit } .
 		Whitespace = " " | "\t" | "\n" | "\r" .
 		Punct = "!"…"/" | ":"…"@" | "["…` + "\"`\"" + ` | "{"…"~" .
 		alpha = "a"…"z" | "A"…"Z" .
 		digit = "0"…"9" .
 		any = "\u0000"…"\uffff" .
-	`))
+	`))}
 	parser = participle.MustBuild(
 		&Root{},
 		participle.Lexer(lexr),
@@ -34,6 +36,9 @@ var (
 func Parse(r io.Reader) (Root, error) {
 	var rules Root
 	err := parser.Parse(r, &rules)
+	if perr, ok := err.(participle.Error); ok {
+		err = &Error{Pos: perr.Token().Pos, Err: errors.New(perr.Message())}
+	}
 	return rules, err
 }
 
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"sync"
+)
+
+// ExecutorPool recycles executors for a set of instructions, such that steady-state execution does not allocate.
+// It is safe for concurrent use.
+type ExecutorPool struct {
+	pool sync.Pool
+}
+
+func NewExecutorPool(ins []Instruction) *ExecutorPool {
+	scratchSize := ScratchSize(ins)
+	return &ExecutorPool{
+		pool: sync.Pool{
+			New: func() interface{} {
+				return newExecutor(ins, scratchSize)
+			},
+		},
+	}
+}
+
+// Get returns an executor prepared to run against the supplied variables.
+func (ep *ExecutorPool) Get(vars Variables) *Executor {
+	i := ep.pool.Get().(*Executor)
+	i.Reset(vars)
+	return i
+}
+
+// Put returns an executor to the pool. The executor must not be used by the caller afterwards.
+func (ep *ExecutorPool) Put(i *Executor) {
+	i.release()
+	ep.pool.Put(i)
+}
@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutorPool(t *testing.T) {
+	ins := []Instruction{
+		{Operation: OperationIsEqual, Ret: 2, Operand1: VarOperand{Name: "a"}, Operand2: StringOperand{Value: "x"}},
+		{Operation: OperationJumpIfZero, Operand1: ScratchOperand{Pos: 2}, Operand2: InstructionPositionOperand{Pos: 3}},
+		{Operation: OperationAddScore, Operand1: ScoreOperand{Name: "s"}, Operand2: IntOperand{Value: 5}},
+	}
+	assert.Equal(t, 3, ScratchSize(ins))
+
+	pool := NewExecutorPool(ins)
+	ex := pool.Get(StringVariables{"a": "x"})
+	ex.Execute()
+	assert.NoError(t, ex.Err())
+	assert.Equal(t, map[string]int{"s": 5}, ex.IntScores())
+	pool.Put(ex)
+
+	ex = pool.Get(StringVariables{"a": "y"})
+	ex.Execute()
+	assert.NoError(t, ex.Err())
+	assert.Equal(t, map[string]int{}, ex.IntScores())
+	pool.Put(ex)
+}
+
+func TestExecutor_release(t *testing.T) {
+	ins := []Instruction{
+		{Operation: OperationAddScore, Operand1: ScoreOperand{Name: "s"}, Operand2: IntOperand{Value: 5}},
+	}
+	ex := NewExecutor(ins, StringVariables{"a": "x"})
+	ex.SetLimits(Limits{Context: context.Background(), MaxInstructions: 10})
+	ex.EnableTrace()
+	ex.Execute()
+	assert.NoError(t, ex.Err())
+	assert.NotEmpty(t, ex.Trace())
+
+	ex.release()
+	assert.Nil(t, ex.vars)
+	assert.Equal(t, Limits{}, ex.limits)
+	assert.Nil(t, ex.Trace())
+}
@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/participle/lexer"
+)
+
+// SchemaField declares a variable expected by a program, along with the kind of value it holds.
+type SchemaField struct {
+	Pos  lexer.Position
+	Name string
+	Kind ValueKind
+}
+
+// Schema declares the variables expected by a program, as given by its input block, in order of declaration. The
+// empty schema declares nothing, such that any variables are accepted.
+type Schema []SchemaField
+
+// NewSchema builds the schema declared by an input block, which may be absent. The block is expected to have passed
+// type checking, such that each type is known.
+func NewSchema(input *Input) Schema {
+	if input == nil {
+		return nil
+	}
+	schema := make(Schema, 0, len(input.Fields))
+	for _, f := range input.Fields {
+		kind, _ := valueKindFromString(f.Type)
+		schema = append(schema, SchemaField{Pos: f.Pos, Name: f.Name, Kind: kind})
+	}
+	return schema
+}
+
+// Validate verifies that each declared variable present within vars holds a value of the declared kind. Variables
+// may be absent, as may be tested for with presence conditions, and those not declared are ignored.
+func (s Schema) Validate(vars Variables) error {
+	for _, f := range s {
+		v, ok := vars.Lookup(f.Name)
+		if ok && !f.accepts(v) {
+			return &Error{Pos: f.Pos, Err: fmt.Errorf("variable %s expects %s, got %s value %q", f.Name, f.Kind, v.Kind(), v)}
+		}
+	}
+	return nil
+}
+
+// accepts reports whether the value is of the field's kind. Strings are accepted for numeric fields where they parse
+// as such, and for bool fields where they are "true" or "false", as are ints for float fields, and integral floats
+// for int fields.
+func (f SchemaField) accepts(v Value) bool {
+	switch f.Kind {
+	case ValueKindInt:
+		_, ok := v.AsInt()
+		return ok
+	case ValueKindFloat:
+		_, ok := v.AsFloat()
+		return ok
+	case ValueKindBool:
+		_, ok := v.AsBool()
+		return ok
+	default:
+		return v.Kind() == f.Kind
+	}
+}
+
+func valueKindFromString(s string) (ValueKind, bool) {
+	for kind, name := range valueKindToStringMap {
+		if name == s {
+			return kind, true
+		}
+	}
+	return 0, false
+}
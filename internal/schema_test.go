@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/participle/lexer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSchema(t *testing.T) {
+	root, err := Parse(strings.NewReader(`input { title: string, views: int, tags: list }`))
+	require.NoError(t, err)
+	assert.Equal(t, Schema{
+		{Pos: lexer.Position{Offset: 8, Line: 1, Column: 9}, Name: "title", Kind: ValueKindString},
+		{Pos: lexer.Position{Offset: 23, Line: 1, Column: 24}, Name: "views", Kind: ValueKindInt},
+		{Pos: lexer.Position{Offset: 35, Line: 1, Column: 36}, Name: "tags", Kind: ValueKindList},
+	}, NewSchema(root.Input))
+	assert.Nil(t, NewSchema(nil))
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema := Schema{
+		{Name: "s", Kind: ValueKindString},
+		{Name: "i", Kind: ValueKindInt},
+		{Name: "f", Kind: ValueKindFloat},
+		{Name: "b", Kind: ValueKindBool},
+		{Name: "l", Kind: ValueKindList},
+	}
+	testCases := []struct {
+		name     string
+		vars     Variables
+		expected string
+	}{
+		{
+			name: "typed values",
+			vars: Values{
+				"s": NewStringValue("x"),
+				"i": NewIntValue(1),
+				"f": NewFloatValue(1.5),
+				"b": NewBoolValue(true),
+				"l": NewListValue([]string{"x"}),
+			},
+		},
+		{
+			name: "parsable strings",
+			vars: StringVariables{"i": "1", "f": "1.5", "b": "false"},
+		},
+		{
+			name: "absent and undeclared variables",
+			vars: StringVariables{"other": "x"},
+		},
+		{
+			name: "int for float",
+			vars: Values{"f": NewIntValue(2)},
+		},
+		{
+			name:     "int for string",
+			vars:     Values{"s": NewIntValue(1)},
+			expected: `variable s expects string, got int value "1"`,
+		},
+		{
+			name:     "non-numeric string for int",
+			vars:     StringVariables{"i": "abc"},
+			expected: `variable i expects int, got string value "abc"`,
+		},
+		{
+			name:     "fractional float for int",
+			vars:     Values{"i": NewFloatValue(1.5)},
+			expected: `variable i expects int, got float value "1.5"`,
+		},
+		{
+			name:     "string for bool",
+			vars:     StringVariables{"b": "yes"},
+			expected: `variable b expects bool, got string value "yes"`,
+		},
+		{
+			name:     "non-canonical string for bool",
+			vars:     StringVariables{"b": "1"},
+			expected: `variable b expects bool, got string value "1"`,
+		},
+		{
+			name:     "string for list",
+			vars:     StringVariables{"l": "x"},
+			expected: `variable l expects list, got string value "x"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			err := schema.Validate(tc.vars)
+			if tc.expected == "" {
+				assert.NoError(tt, err)
+				return
+			}
+			assert.EqualError(tt, err, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/participle/lexer"
+)
+
+// TraceEvent records the evaluation of a condition, or the application of a score change, during execution.
+type TraceEvent struct {
+	Pos       lexer.Position
+	Rule      string
+	Operation Operation
+	Operand1  Operand
+	Operand2  Operand
+	// Result holds the outcome of a condition.
+	Result bool
+	// Score, Before and After describe a score change.
+	Score  string
+	Before Number
+	After  Number
+}
+
+func (te TraceEvent) IsCondition() bool {
+	return te.Operation.IsCondition()
+}
+
+func (te TraceEvent) IsScoreChange() bool {
+	return te.Operation.IsScoreChange()
+}
+
+func (te TraceEvent) String() string {
+	msg := fmt.Sprintf("%s %s", te.Operation, te.Operand1)
+	if te.Operand2 != nil {
+		msg += fmt.Sprintf(" %s", te.Operand2)
+	}
+	if te.IsScoreChange() {
+		msg += fmt.Sprintf(": %s -> %s", te.Before, te.After)
+	} else {
+		msg += fmt.Sprintf(": %t", te.Result)
+	}
+	if te.Rule != "" {
+		msg = fmt.Sprintf("rule %q: %s", te.Rule, msg)
+	}
+	return lexer.FormatError(te.Pos, msg)
+}
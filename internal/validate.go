@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ValidateInstructions verifies that instructions are executable: that each operation is known and has operands of
+// the types it expects, that jump targets lie ahead of the jump and within the instructions, such that execution
+// always terminates, that scratch positions are within the bounds the generator would produce, and that string
+// literals are case folded for the case-insensitive operations.
+func ValidateInstructions(ins []Instruction) error {
+	for n, in := range ins {
+		if err := validateInstruction(in, n, len(ins)); err != nil {
+			return errors.Wrapf(err, "instruction %d", n)
+		}
+	}
+	return nil
+}
+
+// nolint:gocyclo
+func validateInstruction(in Instruction, n, count int) error {
+	if _, ok := operationToStringMap[in.Operation]; !ok {
+		return fmt.Errorf("unknown operation %d", in.Operation)
+	}
+	if in.Ret > ScratchPosition(count) {
+		return fmt.Errorf("scratch position %s out of range", in.Ret)
+	}
+	for _, op := range []Operand{in.Operand1, in.Operand2} {
+		switch o := op.(type) {
+		case ScratchOperand:
+			if o.Pos > ScratchPosition(count) {
+				return fmt.Errorf("scratch position %s out of range", o.Pos)
+			}
+		case InstructionPositionOperand:
+			if o.Pos <= n || o.Pos > count {
+				return fmt.Errorf("jump target %d out of range", o.Pos)
+			}
+		case RegexpOperand:
+			if o.Value == nil {
+				return errors.New("regexp operand missing value")
+			}
+		case StringOperand:
+			// String literals are folded at compile time, with only variables folded upon execution.
+			if in.Operation.IsFold() && o.Value != foldCase(o.Value) {
+				return fmt.Errorf("string operand %q is not case folded for %s", o.Value, in.Operation)
+			}
+		}
+	}
+
+	switch {
+	case in.Operation == OperationMatches || in.Operation == OperationDoesNotMatch:
+		return expectOperands(in, isValueOperand, isRegexpOperand)
+	case in.Operation.IsPresence():
+		return expectOperands(in, isVarOperand, isNoOperand)
+	case in.Operation.IsCondition():
+		return expectOperands(in, isValueOperand, isValueOperand)
+	case in.Operation.IsScoreChange():
+		return expectOperands(in, isScoreOperand, isNumericOperand)
+	case in.Operation == OperationJumpIfZero || in.Operation == OperationJumpIfNotZero:
+		return expectOperands(in, isScratchOperand, isInstructionPositionOperand)
+	case in.Operation == OperationJump:
+		return expectOperands(in, isInstructionPositionOperand, isNoOperand)
+	case in.Operation == OperationNegate:
+		return expectOperands(in, isScratchOperand, isNoOperand)
+	case in.Operation >= OperationAdd && in.Operation <= OperationDiv:
+		return expectOperands(in, isNumericOperand, isNumericOperand)
+	default:
+		return expectOperands(in, isNoOperand, isNoOperand)
+	}
+}
+
+func expectOperands(in Instruction, first, second func(Operand) bool) error {
+	if !first(in.Operand1) {
+		return fmt.Errorf("unexpected first operand %v for %s", in.Operand1, in.Operation)
+	}
+	if !second(in.Operand2) {
+		return fmt.Errorf("unexpected second operand %v for %s", in.Operand2, in.Operation)
+	}
+	return nil
+}
+
+func isNoOperand(op Operand) bool {
+	return op == nil
+}
+
+func isValueOperand(op Operand) bool {
+	switch op.(type) {
+	case VarOperand, StringOperand, IntOperand, FloatOperand, ScoreOperand:
+		return true
+	}
+	return false
+}
+
+func isNumericOperand(op Operand) bool {
+	switch op.(type) {
+	case VarOperand, IntOperand, FloatOperand, ScoreOperand, ScratchOperand:
+		return true
+	}
+	return false
+}
+
+func isVarOperand(op Operand) bool {
+	_, ok := op.(VarOperand)
+	return ok
+}
+
+func isRegexpOperand(op Operand) bool {
+	_, ok := op.(RegexpOperand)
+	return ok
+}
+
+func isScoreOperand(op Operand) bool {
+	_, ok := op.(ScoreOperand)
+	return ok
+}
+
+func isScratchOperand(op Operand) bool {
+	_, ok := op.(ScratchOperand)
+	return ok
+}
+
+func isInstructionPositionOperand(op Operand) bool {
+	_, ok := op.(InstructionPositionOperand)
+	return ok
+}
@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type ValueKind int8
+
+const (
+	ValueKindString ValueKind = iota
+	ValueKindInt
+	ValueKindFloat
+	ValueKindBool
+	ValueKindList
+)
+
+var valueKindToStringMap = map[ValueKind]string{
+	ValueKindString: "string",
+	ValueKindInt:    "int",
+	ValueKindFloat:  "float",
+	ValueKindBool:   "bool",
+	ValueKindList:   "list",
+}
+
+func (k ValueKind) String() string {
+	return valueKindToStringMap[k]
+}
+
+type Value struct {
+	kind ValueKind
+	str  string
+	num  int
+	flt  float64
+	bl   bool
+	list []string
+}
+
+func NewStringValue(s string) Value {
+	return Value{kind: ValueKindString, str: s}
+}
+
+func NewIntValue(n int) Value {
+	return Value{kind: ValueKindInt, num: n}
+}
+
+func NewFloatValue(f float64) Value {
+	return Value{kind: ValueKindFloat, flt: f}
+}
+
+func NewBoolValue(b bool) Value {
+	return Value{kind: ValueKindBool, bl: b}
+}
+
+func NewListValue(l []string) Value {
+	return Value{kind: ValueKindList, list: l}
+}
+
+func (v Value) Kind() ValueKind {
+	return v.kind
+}
+
+// AsString returns the textual form of the value. Lists have no such form.
+func (v Value) AsString() (string, bool) {
+	switch v.kind {
+	case ValueKindString:
+		return v.str, true
+	case ValueKindInt:
+		return strconv.Itoa(v.num), true
+	case ValueKindFloat:
+		return strconv.FormatFloat(v.flt, 'f', -1, 64), true
+	case ValueKindBool:
+		return strconv.FormatBool(v.bl), true
+	}
+	return "", false
+}
+
+// AsInt returns the value as an int. Strings are parsed, and floats are accepted only when integral.
+func (v Value) AsInt() (int, bool) {
+	switch v.kind {
+	case ValueKindString:
+		n, err := strconv.Atoi(v.str)
+		return n, err == nil
+	case ValueKindInt:
+		return v.num, true
+	case ValueKindFloat:
+		n := int(v.flt)
+		return n, float64(n) == v.flt
+	}
+	return 0, false
+}
+
+// AsFloat returns the value as a float. Strings are parsed.
+func (v Value) AsFloat() (float64, bool) {
+	switch v.kind {
+	case ValueKindString:
+		f, err := strconv.ParseFloat(v.str, 64)
+		return f, err == nil
+	case ValueKindInt:
+		return float64(v.num), true
+	case ValueKindFloat:
+		return v.flt, true
+	}
+	return 0, false
+}
+
+// AsBool returns the value as a bool. Only the strings "true" and "false" are accepted, being the textual form of a
+// bool, such that the value compares as the bool would.
+func (v Value) AsBool() (bool, bool) {
+	switch v.kind {
+	case ValueKindString:
+		return v.str == "true", v.str == "true" || v.str == "false"
+	case ValueKindBool:
+		return v.bl, true
+	}
+	return false, false
+}
+
+// AsNumber returns the value as a number. Strings are parsed, resolving to an int where possible.
+func (v Value) AsNumber() (Number, bool) {
+	switch v.kind {
+	case ValueKindString:
+		if n, ok := v.AsInt(); ok {
+			return IntNumber(n), true
+		}
+		f, ok := v.AsFloat()
+		return FloatNumber(f), ok
+	case ValueKindInt:
+		return IntNumber(v.num), true
+	case ValueKindFloat:
+		return FloatNumber(v.flt), true
+	}
+	return Number{}, false
+}
+
+func (v Value) List() []string {
+	return v.list
+}
+
+func (v Value) String() string {
+	if v.kind == ValueKindList {
+		return fmt.Sprintf("[%s]", strings.Join(v.list, ", "))
+	}
+	s, _ := v.AsString()
+	return s
+}
+
+type Variables interface {
+	Lookup(name string) (Value, bool)
+}
+
+type StringVariables map[string]string
+
+func (sv StringVariables) Lookup(name string) (Value, bool) {
+	s, ok := sv[name]
+	return NewStringValue(s), ok
+}
+
+type Values map[string]Value
+
+func (vs Values) Lookup(name string) (Value, bool) {
+	v, ok := vs[name]
+	return v, ok
+}
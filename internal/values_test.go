@@ -0,0 +1,76 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValue_AsString(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    Value
+		expected string
+		ok       bool
+	}{
+		{name: "string", value: NewStringValue("a"), expected: "a", ok: true},
+		{name: "int", value: NewIntValue(1), expected: "1", ok: true},
+		{name: "float", value: NewFloatValue(1.5), expected: "1.5", ok: true},
+		{name: "bool", value: NewBoolValue(true), expected: "true", ok: true},
+		{name: "list", value: NewListValue([]string{"a"}), expected: "", ok: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			s, ok := tc.value.AsString()
+			assert.Equal(tt, tc.expected, s)
+			assert.Equal(tt, tc.ok, ok)
+		})
+	}
+}
+
+func TestValue_AsInt(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    Value
+		expected int
+		ok       bool
+	}{
+		{name: "numeric string", value: NewStringValue("12"), expected: 12, ok: true},
+		{name: "non-numeric string", value: NewStringValue("a"), expected: 0, ok: false},
+		{name: "int", value: NewIntValue(1), expected: 1, ok: true},
+		{name: "integral float", value: NewFloatValue(2), expected: 2, ok: true},
+		{name: "fractional float", value: NewFloatValue(2.5), expected: 2, ok: false},
+		{name: "bool", value: NewBoolValue(true), expected: 0, ok: false},
+		{name: "list", value: NewListValue([]string{"1"}), expected: 0, ok: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			n, ok := tc.value.AsInt()
+			assert.Equal(tt, tc.expected, n)
+			assert.Equal(tt, tc.ok, ok)
+		})
+	}
+}
+
+func TestValue_AsNumber(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    Value
+		expected Number
+		ok       bool
+	}{
+		{name: "int string", value: NewStringValue("12"), expected: IntNumber(12), ok: true},
+		{name: "float string", value: NewStringValue("1.2"), expected: FloatNumber(1.2), ok: true},
+		{name: "non-numeric string", value: NewStringValue("a"), expected: FloatNumber(0), ok: false},
+		{name: "int", value: NewIntValue(1), expected: IntNumber(1), ok: true},
+		{name: "float", value: NewFloatValue(1), expected: FloatNumber(1), ok: true},
+		{name: "bool", value: NewBoolValue(true), expected: Number{}, ok: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(tt *testing.T) {
+			n, ok := tc.value.AsNumber()
+			assert.Equal(tt, tc.expected, n)
+			assert.Equal(tt, tc.ok, ok)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package brulee
+
+import (
+	"context"
+	"time"
+
+	"github.com/nick-jones/brulee/internal"
+)
+
+var (
+	// ErrInstructionLimitExceeded is returned, wrapped in an Error, when a run exceeds MaxInstructions.
+	ErrInstructionLimitExceeded = internal.ErrInstructionLimitExceeded
+	// ErrTimeLimitExceeded is returned, wrapped in an Error, when a run exceeds its Timeout.
+	ErrTimeLimitExceeded = internal.ErrTimeLimitExceeded
+)
+
+// CompileOption configures the compilation of a program.
+type CompileOption func(*compileOptions)
+
+type compileOptions struct {
+	declared bool
+	vars     []string
+}
+
+func newCompileOptions(opts []CompileOption) compileOptions {
+	co := compileOptions{}
+	for _, opt := range opts {
+		opt(&co)
+	}
+	return co
+}
+
+// DeclareVariables restricts the variables the program may reference to those named, such that a reference to any
+// other variable, such as a misspelling, fails compilation. Repeated use adds to the declared variables, and
+// declaring none permits no variables at all.
+func DeclareVariables(names ...string) CompileOption {
+	return func(co *compileOptions) {
+		co.declared = true
+		co.vars = append(co.vars, names...)
+	}
+}
+
+// RunOption configures a single run of a program.
+type RunOption func(*runOptions)
+
+type runOptions struct {
+	trace   bool
+	strict  bool
+	timeout time.Duration
+	limits  internal.Limits
+}
+
+func newRunOptions(ctx context.Context, opts []RunOption) runOptions {
+	ro := runOptions{}
+	for _, opt := range opts {
+		opt(&ro)
+	}
+	ro.limits.Context = ctx
+	if ro.timeout > 0 {
+		ro.limits.Deadline = time.Now().Add(ro.timeout)
+	}
+	return ro
+}
+
+// MaxInstructions aborts the run once more than n instructions have been executed.
+func MaxInstructions(n int) RunOption {
+	return func(ro *runOptions) {
+		ro.limits.MaxInstructions = n
+	}
+}
+
+// Timeout aborts the run once it has taken longer than d. The time taken is checked periodically rather than upon
+// every instruction, so a run may overrun slightly.
+func Timeout(d time.Duration) RunOption {
+	return func(ro *runOptions) {
+		ro.timeout = d
+	}
+}
+
+// Strict fails the run upon reading a variable absent from those supplied, or a score yet to be assigned, rather than
+// treating them as empty and zero respectively. Presence conditions may still be applied to absent variables.
+func Strict() RunOption {
+	return func(ro *runOptions) {
+		ro.strict = true
+	}
+}